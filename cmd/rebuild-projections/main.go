@@ -0,0 +1,38 @@
+// Команда rebuild-projections пересобирает таблицы pull_requests и pr_reviewers
+// из потоков событий PR (pr_events).
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+
+	pgrepo "github.com/you/pr-assign-avito/internal/repository/pg"
+)
+
+func main() {
+	_ = godotenv.Load()
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		log.Fatalf("db connect: %v", err)
+	}
+	defer pool.Close()
+
+	n, err := pgrepo.NewPGRepo(pool).RebuildPRProjections(ctx)
+	if err != nil {
+		log.Fatalf("rebuild failed after %d PRs: %v", n, err)
+	}
+	log.Printf("rebuilt %d PRs", n)
+}
@@ -5,17 +5,27 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 
+	"github.com/you/pr-assign-avito/internal/domain"
 	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/integration"
+	"github.com/you/pr-assign-avito/internal/integration/github"
+	"github.com/you/pr-assign-avito/internal/integration/gitlab"
+	"github.com/you/pr-assign-avito/internal/notify"
 	"github.com/you/pr-assign-avito/internal/repository"
 	pgrepo "github.com/you/pr-assign-avito/internal/repository/pg"
 	transport "github.com/you/pr-assign-avito/internal/transport/http"
 	uc "github.com/you/pr-assign-avito/internal/usecase"
+	"github.com/you/pr-assign-avito/internal/webhook"
+	"github.com/you/pr-assign-avito/internal/worker"
 )
 
 func main() {
@@ -28,6 +38,45 @@ func main() {
 	if port == "" {
 		port = "8080"
 	}
+	strategy := os.Getenv("REVIEWER_STRATEGY")
+	if strategy == "" {
+		strategy = domain.StrategyRandom
+	}
+	if !domain.IsValidReviewerStrategy(strategy) {
+		log.Fatalf("unknown REVIEWER_STRATEGY %q", strategy)
+	}
+	escalationInterval := time.Minute
+	if v := os.Getenv("ESCALATION_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatalf("invalid ESCALATION_INTERVAL %q", v)
+		}
+		escalationInterval = d
+	}
+	outboxInterval := time.Second
+	if v := os.Getenv("OUTBOX_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatalf("invalid OUTBOX_INTERVAL %q", v)
+		}
+		outboxInterval = d
+	}
+	digestAt := 9 * time.Hour
+	if v := os.Getenv("DIGEST_TIME"); v != "" {
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			log.Fatalf("invalid DIGEST_TIME %q, expected HH:MM", v)
+		}
+		digestAt = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	digestLoc, err := time.LoadLocation(os.Getenv("DIGEST_TIMEZONE"))
+	if err != nil {
+		log.Fatalf("invalid DIGEST_TIMEZONE: %v", err)
+	}
+	smtpAddr := os.Getenv("SMTP_ADDR")
+	if smtpAddr != "" && os.Getenv("SMTP_FROM") == "" {
+		log.Fatal("SMTP_FROM required with SMTP_ADDR")
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	pool, err := pgxpool.New(ctx, dbURL)
@@ -42,9 +91,12 @@ func main() {
 	var repo repository.Repo = repoImpl
 
 	logger := infra.NewStdLogger()
-	prUC := uc.NewPRUsecase(repo)
+	prUC := uc.NewPRUsecase(repo, uc.WithDefaultStrategy(strategy))
 
 	handlers := transport.NewHandlers(prUC, repo, logger)
+	handlers.AdminToken = os.Getenv("ADMIN_TOKEN")
+	handlers.GitHubSecret = os.Getenv("GITHUB_WEBHOOK_SECRET")
+	handlers.GitLabToken = os.Getenv("GITLAB_WEBHOOK_TOKEN")
 	router := transport.NewRouter(handlers).(*mux.Router)
 
 	srv := &http.Server{
@@ -55,8 +107,71 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	if escalationInterval > 0 {
+		escalator := worker.NewEscalator(prUC, prUC, repoImpl, logger, escalationInterval)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			escalator.Run(runCtx)
+		}()
+	}
+	if outboxInterval > 0 {
+		sinks := []worker.EventSink{webhook.NewDispatcher(repo, logger), uc.NewNotifierSink(notify.NewChat(repo, logger))}
+		if clients := codeHostClients(); len(clients) > 0 {
+			sinks = append(sinks, integration.NewReviewerSync(repo, repo, logger, clients))
+		}
+		relay := worker.NewOutboxRelay(repoImpl, repoImpl, logger, outboxInterval, sinks...)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			relay.Run(runCtx)
+		}()
+	}
+	if smtpAddr != "" {
+		mailer := notify.NewSMTP(smtpAddr, os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+		digest := worker.NewDigest(repoImpl, mailer, repoImpl, logger, digestAt, digestLoc)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			digest.Run(runCtx)
+		}()
+	}
+
+	// ListenAndServe возвращается сразу после вызова Shutdown, а Shutdown ждёт
+	// завершения обработчиков - дальше останавливаемся только после него
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-runCtx.Done()
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Errorf("server shutdown: %v", err)
+		}
+	}()
+
 	logger.Infof("starting server on :%s", port)
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		logger.Errorf("server error: %v", err)
+		stop()
+	}
+	<-shutdownDone
+	workers.Wait()
+	logger.Infof("server stopped")
+}
+
+// codeHostClients - клиенты API внешних систем, для которых задан токен
+func codeHostClients() map[string]integration.CodeHostClient {
+	clients := map[string]integration.CodeHostClient{}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		clients[domain.ProviderGitHub] = github.NewClient(os.Getenv("GITHUB_API_URL"), token)
+	}
+	if token := os.Getenv("GITLAB_API_TOKEN"); token != "" {
+		clients[domain.ProviderGitLab] = gitlab.NewClient(os.Getenv("GITLAB_API_URL"), token)
 	}
+	return clients
 }
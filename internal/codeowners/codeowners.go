@@ -0,0 +1,132 @@
+// Package codeowners разбирает файлы CODEOWNERS и определяет владельцев путей.
+//
+// Поддерживается синтаксис шаблонов GitHub: `*`, `**`, `?`, привязка к корню
+// через ведущий `/` и каталоги через завершающий `/`. Для пути действует
+// последнее подходящее правило. Владельцы указываются как `@user_id` или
+// `@org/team_name`; адреса почты допускаются, но сервисом не сопоставляются.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule - одно правило CODEOWNERS
+type Rule struct {
+	Line    int
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// Ruleset - правила в порядке следования в файле
+type Ruleset struct {
+	Rules []Rule
+}
+
+// Parse разбирает содержимое CODEOWNERS.
+func Parse(doc string) (Ruleset, error) {
+	var rs Ruleset
+	sc := bufio.NewScanner(strings.NewReader(doc))
+	line := 0
+	for sc.Scan() {
+		line++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		pattern := fields[0]
+		if strings.HasPrefix(pattern, "!") {
+			return Ruleset{}, fmt.Errorf("line %d: negated patterns are not supported", line)
+		}
+		re, err := compile(pattern)
+		if err != nil {
+			return Ruleset{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		var owners []string
+		for _, o := range fields[1:] {
+			if strings.HasPrefix(o, "#") {
+				break
+			}
+			if !strings.HasPrefix(o, "@") && !strings.Contains(o, "@") {
+				return Ruleset{}, fmt.Errorf("line %d: invalid owner %q", line, o)
+			}
+			owners = append(owners, o)
+		}
+		rs.Rules = append(rs.Rules, Rule{Line: line, Pattern: pattern, Owners: owners, re: re})
+	}
+	return rs, sc.Err()
+}
+
+// Match возвращает последнее правило, подходящее для path.
+func (rs Ruleset) Match(path string) (Rule, bool) {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(rs.Rules) - 1; i >= 0; i-- {
+		if rs.Rules[i].re.MatchString(path) {
+			return rs.Rules[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// ParseOwner разбирает владельца: для `@org/team` возвращает имя команды,
+// для `@user` - идентификатор пользователя. Почтовые адреса не распознаются.
+func ParseOwner(owner string) (userID, teamName string, ok bool) {
+	if !strings.HasPrefix(owner, "@") {
+		return "", "", false
+	}
+	name := strings.TrimPrefix(owner, "@")
+	if i := strings.Index(name, "/"); i >= 0 {
+		return "", name[i+1:], name[i+1:] != ""
+	}
+	return name, "", name != ""
+}
+
+// compile переводит шаблон CODEOWNERS в регулярное выражение
+func compile(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	dir := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("empty pattern %q", pattern)
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	last := p[strings.LastIndex(p, "/")+1:]
+	switch {
+	case dir:
+		b.WriteString("/.*$")
+	case strings.ContainsAny(last, "*?"):
+		// шаблон вида docs/* не распространяется на вложенные каталоги
+		b.WriteString("$")
+	default:
+		// шаблон без wildcard в конце может обозначать каталог
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
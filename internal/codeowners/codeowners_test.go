@@ -0,0 +1,70 @@
+package codeowners
+
+import "testing"
+
+const sample = `# комментарий
+*           @org/backend
+*.js        @u1
+/docs/      @u2 @org/docs # владельцы документации
+api/*       @u3
+**/migrations @u4
+`
+
+func TestMatch(t *testing.T) {
+	rs, err := Parse(sample)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	tests := []struct {
+		path string
+		line int
+	}{
+		{"main.go", 2},
+		{"web/app.js", 3},
+		{"docs/guide.md", 4},
+		{"docs/nested/page.md", 4},
+		{"src/docs/readme.md", 2},
+		{"api/handler.go", 5},
+		{"api/v1/handler.go", 2},
+		{"migrations/0001.sql", 6},
+		{"db/migrations/0001.sql", 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rule, ok := rs.Match(tt.path)
+			if !ok {
+				t.Fatalf("expected a rule for %s", tt.path)
+			}
+			if rule.Line != tt.line {
+				t.Fatalf("expected rule on line %d, got %d (%s)", tt.line, rule.Line, rule.Pattern)
+			}
+		})
+	}
+	rule, _ := rs.Match("docs/guide.md")
+	if len(rule.Owners) != 2 || rule.Owners[1] != "@org/docs" {
+		t.Fatalf("unexpected owners %v", rule.Owners)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, doc := range []string{
+		"!docs/ @u1",
+		"docs/ owner",
+	} {
+		if _, err := Parse(doc); err == nil {
+			t.Fatalf("expected error for %q", doc)
+		}
+	}
+}
+
+func TestParseOwner(t *testing.T) {
+	if id, team, ok := ParseOwner("@u1"); !ok || id != "u1" || team != "" {
+		t.Fatalf("unexpected result for user owner: %q %q %v", id, team, ok)
+	}
+	if id, team, ok := ParseOwner("@org/backend"); !ok || id != "" || team != "backend" {
+		t.Fatalf("unexpected result for team owner: %q %q %v", id, team, ok)
+	}
+	if _, _, ok := ParseOwner("dev@example.com"); ok {
+		t.Fatalf("email owners must not be resolved")
+	}
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Absence - период отсутствия пользователя (отпуск, больничный и т.п.), [StartsAt, EndsAt)
+type Absence struct {
+	ID       int       `json:"absence_id"`
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason,omitempty"`
+	// переназначить открытые ревью пользователя, когда отсутствие начнётся
+	ReassignOpenReviews bool       `json:"reassign_open_reviews"`
+	ReassignedAt        *time.Time `json:"reassigned_at,omitempty"`
+}
+
+func (a Absence) Covers(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AssignmentReason - почему изменился состав ревьюверов PR
+type AssignmentReason string
+
+const (
+	ReasonPRCreated           AssignmentReason = "pr_created"           // назначение при создании PR
+	ReasonManualReassign      AssignmentReason = "manual_reassign"      // замена через /pullRequest/reassign
+	ReasonReviewerUnavailable AssignmentReason = "reviewer_unavailable" // ревьювер деактивирован или отсутствует
+	ReasonTeamDeactivation    AssignmentReason = "team_deactivation"    // массовая деактивация в команде
+	ReasonEscalationReassign  AssignmentReason = "escalation_reassign"  // эскалация заменила зависшего ревьювера
+	ReasonEscalationAdd       AssignmentReason = "escalation_add"       // эскалация добавила ревьювера в помощь
+)
+
+// AssignmentEvent - запись журнала назначений. OldReviewerID пуст, если ревьювер
+// только добавлен, NewReviewerID - если только снят.
+type AssignmentEvent struct {
+	ID            int64            `json:"id"`
+	PullRequestID string           `json:"pull_request_id"`
+	Actor         string           `json:"actor"`
+	OldReviewerID string           `json:"old_reviewer_id,omitempty"`
+	NewReviewerID string           `json:"new_reviewer_id,omitempty"`
+	Reason        AssignmentReason `json:"reason"`
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// ActorSystem - инициатор изменений, для которых он не указан
+const ActorSystem = "system"
+
+type actorKey struct{}
+
+// WithActor запоминает в ctx, кто инициировал изменения, для журнала назначений.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom возвращает инициатора из ctx или ActorSystem.
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
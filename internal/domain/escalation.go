@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// EscalationAction - что делать с ревью, которое зависло дольше порога команды
+type EscalationAction string
+
+const (
+	EscalationReassign    EscalationAction = "reassign"     // заменить ревьювера
+	EscalationAddReviewer EscalationAction = "add_reviewer" // добавить ещё одного ревьювера
+)
+
+func IsValidEscalationAction(a EscalationAction) bool {
+	return a == EscalationReassign || a == EscalationAddReviewer
+}
+
+// StaleReview - назначение, по которому ревьювер не ответил дольше порога эскалации
+type StaleReview struct {
+	PullRequestID string
+	ReviewerID    string
+	AssignedAt    time.Time
+	Action        EscalationAction
+}
+
+// Escalation - выполненная эскалация зависшего ревью
+type Escalation struct {
+	PullRequestID string           `json:"pull_request_id"`
+	ReviewerID    string           `json:"reviewer_id"`
+	Action        EscalationAction `json:"action"`
+	NewReviewerID string           `json:"new_reviewer_id"`
+}
+
+// EscalationReport - итог одного прохода эскалации
+type EscalationReport struct {
+	Escalated []Escalation      `json:"escalated"`
+	Failed    []ReassignFailure `json:"failed"`
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Типы событий, о которых сервис уведомляет подписчиков
+const (
+	EventPRCreated          = "pr.created"
+	EventReviewerAssigned   = "reviewer.assigned"
+	EventReviewerReassigned = "reviewer.reassigned"
+	EventPRMerged           = "pr.merged"
+)
+
+func IsValidEventType(t string) bool {
+	switch t {
+	case EventPRCreated, EventReviewerAssigned, EventReviewerReassigned, EventPRMerged:
+		return true
+	}
+	return false
+}
+
+// Event - событие для внешних подписчиков
+type Event struct {
+	ID         string      `json:"event_id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+func NewEvent(eventType string, data interface{}) Event {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return Event{ID: hex.EncodeToString(b), Type: eventType, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+// ReviewerAssignment - данные событий reviewer.assigned и reviewer.reassigned
+type ReviewerAssignment struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	// ревьювер, которого заменили; только для reviewer.reassigned
+	OldReviewerID string `json:"old_reviewer_id,omitempty"`
+}
+
+func NewReviewerAssignedEvent(prID, reviewerID string) Event {
+	return NewEvent(EventReviewerAssigned, ReviewerAssignment{PullRequestID: prID, ReviewerID: reviewerID})
+}
+
+func NewReviewerReassignedEvent(r Replacement) Event {
+	return NewEvent(EventReviewerReassigned, ReviewerAssignment{
+		PullRequestID: r.PullRequestID,
+		ReviewerID:    r.NewReviewerID,
+		OldReviewerID: r.OldReviewerID,
+	})
+}
+
+// OutboxEvent - событие из outbox вместе с состоянием его доставки
+type OutboxEvent struct {
+	// Seq - порядковый номер записи в outbox
+	Seq      int64
+	Event    Event
+	Attempts int
+	// DeliveredTo - имена получателей, уже принявших событие в прошлых попытках
+	DeliveredTo []string
+}
+
+// Webhook - подписка на события; пустой Events - все события
+type Webhook struct {
+	ID        int       `json:"webhook_id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wants сообщает, подписан ли вебхук на события типа eventType.
+func (w Webhook) Wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
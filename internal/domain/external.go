@@ -0,0 +1,22 @@
+package domain
+
+// Внешние системы, из которых приходят PR
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+func IsValidProvider(p string) bool {
+	switch p {
+	case ProviderGitHub, ProviderGitLab:
+		return true
+	}
+	return false
+}
+
+// ExternalAccount связывает логин во внешней системе с пользователем сервиса
+type ExternalAccount struct {
+	Provider string `json:"provider"`
+	Login    string `json:"login"`
+	UserID   string `json:"user_id"`
+}
@@ -2,12 +2,67 @@ package domain
 
 import "time"
 
+// PRStatus - статус жизненного цикла PR
+type PRStatus string
+
+const (
+	StatusDraft    PRStatus = "DRAFT"
+	StatusOpen     PRStatus = "OPEN"
+	StatusReopened PRStatus = "REOPENED"
+	StatusMerged   PRStatus = "MERGED"
+	StatusClosed   PRStatus = "CLOSED" // отклонён без merge
+)
+
+// transitions - допустимые переходы между статусами
+var transitions = map[PRStatus][]PRStatus{
+	StatusDraft:    {StatusOpen, StatusClosed},
+	StatusOpen:     {StatusDraft, StatusMerged, StatusClosed},
+	StatusReopened: {StatusDraft, StatusMerged, StatusClosed},
+	StatusClosed:   {StatusReopened},
+}
+
+// CanTransition сообщает, допустим ли переход из from в to.
+// Повторный перевод в текущий статус допустим и ничего не меняет.
+func CanTransition(from, to PRStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen сообщает, ожидает ли PR ревью; такие PR учитываются в нагрузке ревьюверов.
+func (s PRStatus) IsOpen() bool {
+	return s == StatusOpen || s == StatusReopened
+}
+
+// CanChangeReviewers сообщает, можно ли менять ревьюверов PR в этом статусе.
+func (s PRStatus) CanChangeReviewers() bool {
+	return s == StatusDraft || s.IsOpen()
+}
+
 type PullRequest struct {
-    ID        string     `json:"pull_request_id"`
-    Title     string     `json:"pull_request_name"`
-    AuthorID  string     `json:"author_id"`
-    Status    string     `json:"status"`
-    Reviewers []string   `json:"assigned_reviewers"`
-    CreatedAt time.Time  `json:"createdAt"`
-    MergedAt  *time.Time `json:"mergedAt,omitempty"`
+	ID        string     `json:"pull_request_id"`
+	Title     string     `json:"pull_request_name"`
+	AuthorID  string     `json:"author_id"`
+	Status    PRStatus   `json:"status"`
+	Reviewers []string   `json:"assigned_reviewers"`
+	CreatedAt time.Time  `json:"createdAt"`
+	MergedAt  *time.Time `json:"mergedAt,omitempty"`
+	// команда, из которой взят каждый ревьювер (user_id -> team_name)
+	ReviewerTeams map[string]string `json:"reviewer_teams,omitempty"`
+	// затрагиваемые области, по ним подбираются ревьюверы с нужной экспертизой
+	Tags []string `json:"tags,omitempty"`
+	// последнее решение каждого назначенного ревьювера (user_id -> review)
+	ReviewDecisions map[string]Review `json:"review_decisions,omitempty"`
+	// кто смёржил PR в обход политики merge команды
+	MergeForcedBy string `json:"merge_forced_by,omitempty"`
+	// внешняя система (github, gitlab), из которой пришёл PR
+	Source string `json:"source,omitempty"`
+	// изменённые файлы, по ним ищутся владельцы в CODEOWNERS; не сохраняются
+	ChangedFiles []string `json:"-"`
 }
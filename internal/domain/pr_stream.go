@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Типы событий потока PR. Поток - источник истины о PR и его ревьюверах,
+// таблицы pull_requests и pr_reviewers - его проекции.
+const (
+	PREventCreated           = "created"
+	PREventReviewerAssigned  = "reviewer_assigned"
+	PREventReviewerReplaced  = "reviewer_replaced"
+	PREventReviewerEscalated = "reviewer_escalated"
+	PREventStatusChanged     = "status_changed"
+	PREventMerged            = "merged"
+)
+
+var (
+	ErrEventOutOfOrder = errors.New("pr event out of order")
+	ErrUnknownPREvent  = errors.New("unknown pr event")
+)
+
+// PREvent - событие потока PR. Version нумерует события PR подряд с 1.
+type PREvent struct {
+	PullRequestID string
+	Version       int
+	Type          string
+	OccurredAt    time.Time
+	Data          PREventData
+}
+
+// PREventData - данные события; заполнены только поля его типа
+type PREventData struct {
+	// created
+	Title    string   `json:"title,omitempty"`
+	AuthorID string   `json:"author_id,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Source   string   `json:"source,omitempty"`
+	// created в потоках, собранных миграцией для уже существовавших PR: до этого
+	// момента поток восстановлен по неполным данным
+	HistoryFrom *time.Time `json:"history_from,omitempty"`
+	// created, status_changed
+	Status PRStatus `json:"status,omitempty"`
+	// reviewer_*
+	ReviewerID    string `json:"reviewer_id,omitempty"`
+	OldReviewerID string `json:"old_reviewer_id,omitempty"`
+	// merged
+	ForcedBy string `json:"forced_by,omitempty"`
+}
+
+func NewPRCreated(pr PullRequest, status PRStatus) PREvent {
+	return PREvent{PullRequestID: pr.ID, Type: PREventCreated, Data: PREventData{
+		Title: pr.Title, AuthorID: pr.AuthorID, Tags: pr.Tags, Source: pr.Source, Status: status,
+	}}
+}
+
+func NewPRReviewerAssigned(prID, reviewerID string) PREvent {
+	return PREvent{PullRequestID: prID, Type: PREventReviewerAssigned, Data: PREventData{ReviewerID: reviewerID}}
+}
+
+func NewPRReviewerReplaced(r Replacement) PREvent {
+	return PREvent{PullRequestID: r.PullRequestID, Type: PREventReviewerReplaced, Data: PREventData{
+		ReviewerID: r.NewReviewerID, OldReviewerID: r.OldReviewerID,
+	}}
+}
+
+func NewPRReviewerEscalated(prID, reviewerID string) PREvent {
+	return PREvent{PullRequestID: prID, Type: PREventReviewerEscalated, Data: PREventData{ReviewerID: reviewerID}}
+}
+
+func NewPRStatusChanged(prID string, to PRStatus) PREvent {
+	return PREvent{PullRequestID: prID, Type: PREventStatusChanged, Data: PREventData{Status: to}}
+}
+
+func NewPRMerged(prID, forcedBy string) PREvent {
+	return PREvent{PullRequestID: prID, Type: PREventMerged, Data: PREventData{ForcedBy: forcedBy}}
+}
+
+// Assignment - назначение ревьювера в состоянии PR
+type Assignment struct {
+	ReviewerID  string
+	AssignedAt  time.Time
+	EscalatedAt *time.Time
+}
+
+// PRState - PR, восстановленный из потока событий. Решения ревьюверов в поток
+// не входят, PR.ReviewDecisions не заполняется.
+type PRState struct {
+	PR          PullRequest
+	Assignments []Assignment
+	Version     int
+	// HistoryFrom - с какого момента поток полон; nil - с создания PR
+	HistoryFrom *time.Time
+}
+
+// Exists сообщает, было ли применено событие created.
+func (s *PRState) Exists() bool {
+	return s.Version > 0
+}
+
+// Apply применяет к состоянию очередное событие потока. События - уже
+// случившиеся факты, поэтому проверяется только порядок, а не допустимость.
+func (s *PRState) Apply(e PREvent) error {
+	if e.Version != s.Version+1 || (e.Type == PREventCreated) != (s.Version == 0) {
+		return fmt.Errorf("%w: PR %s version %d %s after version %d", ErrEventOutOfOrder, e.PullRequestID, e.Version, e.Type, s.Version)
+	}
+	d := e.Data
+	switch e.Type {
+	case PREventCreated:
+		s.PR = PullRequest{
+			ID:        e.PullRequestID,
+			Title:     d.Title,
+			AuthorID:  d.AuthorID,
+			Status:    d.Status,
+			Tags:      d.Tags,
+			Source:    d.Source,
+			CreatedAt: e.OccurredAt,
+		}
+		s.HistoryFrom = d.HistoryFrom
+	case PREventReviewerAssigned:
+		s.Assignments = append(s.Assignments, Assignment{ReviewerID: d.ReviewerID, AssignedAt: e.OccurredAt})
+	case PREventReviewerReplaced:
+		s.unassign(d.OldReviewerID)
+		s.Assignments = append(s.Assignments, Assignment{ReviewerID: d.ReviewerID, AssignedAt: e.OccurredAt})
+	case PREventReviewerEscalated:
+		for i := range s.Assignments {
+			if s.Assignments[i].ReviewerID == d.ReviewerID {
+				at := e.OccurredAt
+				s.Assignments[i].EscalatedAt = &at
+			}
+		}
+	case PREventStatusChanged:
+		s.PR.Status = d.Status
+	case PREventMerged:
+		at := e.OccurredAt
+		s.PR.Status = StatusMerged
+		s.PR.MergedAt = &at
+		s.PR.MergeForcedBy = d.ForcedBy
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownPREvent, e.Type)
+	}
+	s.Version = e.Version
+	s.PR.Reviewers = s.reviewers()
+	return nil
+}
+
+// HistoryKnownAt сообщает, можно ли доверять состоянию PR на момент at.
+func (s *PRState) HistoryKnownAt(at time.Time) bool {
+	return s.HistoryFrom == nil || !at.Before(*s.HistoryFrom)
+}
+
+// IsAssigned сообщает, назначен ли reviewerID на PR.
+func (s *PRState) IsAssigned(reviewerID string) bool {
+	for _, a := range s.Assignments {
+		if a.ReviewerID == reviewerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PRState) unassign(reviewerID string) {
+	kept := s.Assignments[:0]
+	for _, a := range s.Assignments {
+		if a.ReviewerID != reviewerID {
+			kept = append(kept, a)
+		}
+	}
+	s.Assignments = kept
+}
+
+func (s *PRState) reviewers() []string {
+	ids := make([]string, 0, len(s.Assignments))
+	for _, a := range s.Assignments {
+		ids = append(ids, a.ReviewerID)
+	}
+	return ids
+}
+
+// ReplayPR восстанавливает состояние PR из его событий, упорядоченных по версии.
+func ReplayPR(events []PREvent) (PRState, error) {
+	var s PRState
+	for _, e := range events {
+		if err := s.Apply(e); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// ReplayPRUntil восстанавливает состояние PR на момент at по событиям, случившимся не позже at.
+// Без таких событий состояние пустое (PR ещё не создан).
+func ReplayPRUntil(events []PREvent, at time.Time) (PRState, error) {
+	var s PRState
+	for _, e := range events {
+		if e.OccurredAt.After(at) {
+			break
+		}
+		if err := s.Apply(e); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func stream(events ...PREvent) []PREvent {
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	for i := range events {
+		events[i].Version = i + 1
+		events[i].OccurredAt = start.Add(time.Duration(i) * time.Hour)
+	}
+	return events
+}
+
+func TestReplayPR(t *testing.T) {
+	events := stream(
+		NewPRCreated(PullRequest{ID: "pr1", Title: "Add search", AuthorID: "u1", Tags: []string{"backend"}, Source: ProviderGitHub}, StatusOpen),
+		NewPRReviewerAssigned("pr1", "u2"),
+		NewPRReviewerAssigned("pr1", "u3"),
+		NewPRReviewerEscalated("pr1", "u2"),
+		NewPRReviewerReplaced(Replacement{PullRequestID: "pr1", OldReviewerID: "u2", NewReviewerID: "u4"}),
+		NewPRMerged("pr1", "admin"),
+	)
+	s, err := ReplayPR(events)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if s.Version != 6 || s.PR.Title != "Add search" || s.PR.Status != StatusMerged || s.PR.MergeForcedBy != "admin" {
+		t.Fatalf("unexpected state: %+v", s.PR)
+	}
+	if !s.PR.CreatedAt.Equal(events[0].OccurredAt) || s.PR.MergedAt == nil || !s.PR.MergedAt.Equal(events[5].OccurredAt) {
+		t.Fatalf("unexpected timestamps: %+v", s.PR)
+	}
+	if !reflect.DeepEqual(s.PR.Reviewers, []string{"u3", "u4"}) {
+		t.Fatalf("expected reviewers [u3 u4], got %v", s.PR.Reviewers)
+	}
+	if a := s.Assignments[1]; !a.AssignedAt.Equal(events[4].OccurredAt) || a.EscalatedAt != nil {
+		t.Fatalf("replacement must start a fresh assignment, got %+v", a)
+	}
+}
+
+func TestReplayPR_Errors(t *testing.T) {
+	created := NewPRCreated(PullRequest{ID: "pr1", AuthorID: "u1"}, StatusOpen)
+	cases := map[string]struct {
+		events []PREvent
+		want   error
+	}{
+		"gap":           {append(stream(created), PREvent{PullRequestID: "pr1", Version: 3, Type: PREventMerged}), ErrEventOutOfOrder},
+		"not created":   {stream(NewPRReviewerAssigned("pr1", "u2")), ErrEventOutOfOrder},
+		"created twice": {stream(created, created), ErrEventOutOfOrder},
+		"unknown type":  {stream(created, PREvent{PullRequestID: "pr1", Type: "renamed"}), ErrUnknownPREvent},
+	}
+	for name, c := range cases {
+		if _, err := ReplayPR(c.events); !errors.Is(err, c.want) {
+			t.Fatalf("%s: expected %v, got %v", name, c.want, err)
+		}
+	}
+}
+
+func TestReplayPRUntil(t *testing.T) {
+	events := stream(
+		NewPRCreated(PullRequest{ID: "pr1", AuthorID: "u1"}, StatusOpen),
+		NewPRReviewerAssigned("pr1", "u2"),
+		NewPRReviewerReplaced(Replacement{PullRequestID: "pr1", OldReviewerID: "u2", NewReviewerID: "u3"}),
+		NewPRMerged("pr1", ""),
+	)
+	cases := []struct {
+		at        time.Time
+		exists    bool
+		status    PRStatus
+		reviewers []string
+	}{
+		{events[0].OccurredAt.Add(-time.Second), false, "", nil},
+		{events[1].OccurredAt, true, StatusOpen, []string{"u2"}},
+		{events[2].OccurredAt.Add(30 * time.Minute), true, StatusOpen, []string{"u3"}},
+		{events[3].OccurredAt, true, StatusMerged, []string{"u3"}},
+	}
+	for _, c := range cases {
+		s, err := ReplayPRUntil(events, c.at)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if s.Exists() != c.exists || s.PR.Status != c.status || !reflect.DeepEqual(s.PR.Reviewers, c.reviewers) {
+			t.Fatalf("at %s: unexpected state %+v", c.at, s.PR)
+		}
+	}
+}
+
+func TestReplayPRUntil_HistoryFrom(t *testing.T) {
+	created := NewPRCreated(PullRequest{ID: "pr1", AuthorID: "u1"}, StatusOpen)
+	events := stream(created, NewPRStatusChanged("pr1", StatusClosed))
+	from := events[1].OccurredAt.Add(time.Hour)
+	events[0].Data.HistoryFrom = &from
+
+	s, err := ReplayPRUntil(events, events[1].OccurredAt)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if s.HistoryKnownAt(events[1].OccurredAt) {
+		t.Fatalf("expected history unknown before %s", from)
+	}
+	if !s.HistoryKnownAt(from) {
+		t.Fatalf("expected history known from %s", from)
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+// Replacement - выполненная замена ревьювера на PR
+type Replacement struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id"`
+}
+
+// ReassignFailure - PR, на котором ревьювера заменить не удалось
+type ReassignFailure struct {
+	PullRequestID string `json:"pull_request_id"`
+	ReviewerID    string `json:"reviewer_id"`
+	Reason        string `json:"reason"`
+}
+
+// ReassignReport - итог переназначения всех открытых ревью пользователя
+type ReassignReport struct {
+	Reassigned []Replacement     `json:"reassigned"`
+	Failed     []ReassignFailure `json:"failed"`
+}
+
+func NewReassignReport() ReassignReport {
+	return ReassignReport{Reassigned: []Replacement{}, Failed: []ReassignFailure{}}
+}
+
+// TeamDeactivation - итог массовой деактивации пользователей команды
+type TeamDeactivation struct {
+	TeamName         string   `json:"team_name"`
+	DeactivatedUsers []string `json:"deactivated_users"`
+	ReassignReport
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// ReviewDecision - решение ревьювера по PR
+type ReviewDecision string
+
+const (
+	DecisionApproved         ReviewDecision = "APPROVED"
+	DecisionChangesRequested ReviewDecision = "CHANGES_REQUESTED"
+	DecisionCommented        ReviewDecision = "COMMENTED"
+)
+
+func IsValidReviewDecision(d ReviewDecision) bool {
+	switch d {
+	case DecisionApproved, DecisionChangesRequested, DecisionCommented:
+		return true
+	}
+	return false
+}
+
+// Review - решение, записанное ревьювером; история хранится полностью
+type Review struct {
+	ID            int            `json:"-"`
+	PullRequestID string         `json:"pull_request_id"`
+	ReviewerID    string         `json:"reviewer_id"`
+	Decision      ReviewDecision `json:"decision"`
+	Comment       string         `json:"comment,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
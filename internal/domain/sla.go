@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// OverdueReview - назначенный ревьювер, не ответивший на PR в срок SLA
+type OverdueReview struct {
+	ReviewerID string    `json:"reviewer_id"`
+	AssignedAt time.Time `json:"assigned_at"`
+	DueAt      time.Time `json:"due_at"`
+}
+
+// OverduePR - открытый PR с просроченными ревью
+type OverduePR struct {
+	ID        string          `json:"pull_request_id"`
+	Title     string          `json:"pull_request_name"`
+	AuthorID  string          `json:"author_id"`
+	Status    PRStatus        `json:"status"`
+	Reviewers []OverdueReview `json:"overdue_reviewers"`
+}
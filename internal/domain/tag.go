@@ -0,0 +1,22 @@
+package domain
+
+import "strings"
+
+// NormalizeTags приводит теги к нижнему регистру, убирает пробелы по краям и дубликаты.
+// Возвращает false, если среди тегов есть пустой.
+func NormalizeTags(tags []string) ([]string, bool) {
+	res := make([]string, 0, len(tags))
+	seen := map[string]struct{}{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			return nil, false
+		}
+		if _, dup := seen[t]; dup {
+			continue
+		}
+		seen[t] = struct{}{}
+		res = append(res, t)
+	}
+	return res, true
+}
@@ -1,6 +1,86 @@
 package domain
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Стратегии выбора ревьюверов
+const (
+	StrategyRandom         = "random"
+	StrategyRoundRobin     = "round_robin"
+	StrategyLeastLoaded    = "least_loaded"
+	StrategyWeightedRandom = "weighted_random"
+)
+
+// DefaultMaxReviewers - число ревьюверов для команд без собственной настройки
+const DefaultMaxReviewers = 2
+
 type Team struct {
-    ID   int    `json:"-"`
-    Name string `json:"team_name"`
+	ID               int    `json:"-"`
+	Name             string `json:"team_name"`
+	ReviewerStrategy string `json:"reviewer_strategy,omitempty"`
+	MinReviewers     int    `json:"min_reviewers"`
+	MaxReviewers     int    `json:"max_reviewers"`
+	// резервные команды в порядке приоритета, из них берутся ревьюверы,
+	// когда в команде не хватает кандидатов
+	FallbackTeams   []string `json:"fallback_teams,omitempty"`
+	FallbackTeamIDs []int    `json:"-"`
+	// содержимое CODEOWNERS команды, пустая строка - не загружен
+	Codeowners string `json:"-"`
+	// политика merge: сколько нужно одобрений и можно ли мержить
+	// при неснятом CHANGES_REQUESTED
+	RequiredApprovals     int  `json:"required_approvals"`
+	AllowChangesRequested bool `json:"allow_changes_requested"`
+	// сколько часов ревьювер может не отвечать на PR, 0 - без SLA
+	ReviewSLAHours int `json:"review_sla_hours"`
+	// через сколько часов без решения ревью эскалируется (0 - никогда) и как
+	EscalationHours  int              `json:"escalation_hours"`
+	EscalationAction EscalationAction `json:"escalation_action"`
+	// incoming webhook чата команды (пустой - уведомления выключены) и канал,
+	// если он отличается от канала вебхука
+	ChatWebhookURL string `json:"-"`
+	ChatChannel    string `json:"chat_channel,omitempty"`
+}
+
+// ReviewerLimits возвращает минимальное и максимальное число ревьюверов на PR.
+func (t Team) ReviewerLimits() (minReviewers, maxReviewers int) {
+	maxReviewers = t.MaxReviewers
+	if maxReviewers <= 0 {
+		maxReviewers = DefaultMaxReviewers
+	}
+	return t.MinReviewers, maxReviewers
+}
+
+// MergeBlockers возвращает условия политики merge команды, которые не выполнены
+// при последних решениях ревьюверов decisions. Пустой результат - merge разрешён.
+func (t Team) MergeBlockers(decisions map[string]Review) []string {
+	var unmet []string
+	approvals := 0
+	var requested []string
+	for reviewerID, r := range decisions {
+		switch r.Decision {
+		case DecisionApproved:
+			approvals++
+		case DecisionChangesRequested:
+			requested = append(requested, reviewerID)
+		}
+	}
+	if approvals < t.RequiredApprovals {
+		unmet = append(unmet, fmt.Sprintf("required %d approvals, got %d", t.RequiredApprovals, approvals))
+	}
+	if !t.AllowChangesRequested && len(requested) > 0 {
+		sort.Strings(requested)
+		unmet = append(unmet, "changes requested by "+strings.Join(requested, ", "))
+	}
+	return unmet
+}
+
+func IsValidReviewerStrategy(s string) bool {
+	switch s {
+	case StrategyRandom, StrategyRoundRobin, StrategyLeastLoaded, StrategyWeightedRandom:
+		return true
+	}
+	return false
 }
@@ -1,9 +1,79 @@
 package domain
 
 type User struct {
-	ID       string `json:"user_id"`
-	Username string `json:"username"`
-	TeamID   int    `json:"-"`
-	TeamName string `json:"team_name,omitempty"`
-	IsActive bool   `json:"is_active"`
+	ID                   string `json:"user_id"`
+	Username             string `json:"username"`
+	TeamID               int    `json:"-"`
+	TeamName             string `json:"team_name,omitempty"`
+	IsActive             bool   `json:"is_active"`
+	MaxConcurrentReviews int    `json:"max_concurrent_reviews"` // 0 - без ограничения
+	OpenReviews          int    `json:"open_reviews"`           // вычисляется по pr_reviewers
+	// области экспертизы пользователя
+	Tags []string `json:"tags,omitempty"`
+	// упоминание пользователя в чате; пустое - пишется username
+	ChatHandle string `json:"chat_handle,omitempty"`
+	// адрес для ежедневного дайджеста ожидающих ревью и отказ от него
+	Email        string `json:"email,omitempty"`
+	DigestOptOut bool   `json:"digest_opt_out"`
+}
+
+// Mention возвращает, как обратиться к пользователю в чате.
+func (u User) Mention() string {
+	if u.ChatHandle != "" {
+		return u.ChatHandle
+	}
+	return u.Username
+}
+
+// HasCapacity сообщает, может ли пользователь взять ещё одно ревью.
+func (u User) HasCapacity() bool {
+	return u.MaxConcurrentReviews == 0 || u.OpenReviews < u.MaxConcurrentReviews
+}
+
+// HasAnyTag сообщает, пересекается ли экспертиза пользователя с тегами.
+func (u User) HasAnyTag(tags []string) bool {
+	for _, own := range u.Tags {
+		for _, t := range tags {
+			if own == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TeamMember - участник из запроса /team/add. Nil-поля в запросе не переданы: у уже
+// существующего пользователя они не меняются, новый получает значения по умолчанию.
+type TeamMember struct {
+	ID                   string
+	Username             string
+	IsActive             bool
+	MaxConcurrentReviews *int
+	Tags                 []string
+	ChatHandle           *string
+	Email                *string
+	DigestOptOut         *bool
+}
+
+// ApplyTo возвращает пользователя u с переданными полями участника.
+func (m TeamMember) ApplyTo(u User) User {
+	u.ID = m.ID
+	u.Username = m.Username
+	u.IsActive = m.IsActive
+	if m.MaxConcurrentReviews != nil {
+		u.MaxConcurrentReviews = *m.MaxConcurrentReviews
+	}
+	if m.Tags != nil {
+		u.Tags = m.Tags
+	}
+	if m.ChatHandle != nil {
+		u.ChatHandle = *m.ChatHandle
+	}
+	if m.Email != nil {
+		u.Email = *m.Email
+	}
+	if m.DigestOptOut != nil {
+		u.DigestOptOut = *m.DigestOptOut
+	}
+	return u
 }
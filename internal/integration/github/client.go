@@ -0,0 +1,86 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/integration"
+)
+
+// DefaultAPIURL - REST API github.com; для GitHub Enterprise - https://<host>/api/v3
+const DefaultAPIURL = "https://api.github.com"
+
+const apiVersion = "2022-11-28"
+
+// Client запрашивает ревью на PR через REST API GitHub.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ParsePRID разбирает идентификатор, построенный PRID.
+func ParsePRID(prID string) (fullName string, number int, ok bool) {
+	i := strings.LastIndex(prID, "#")
+	if i <= 0 || !strings.Contains(prID[:i], "/") {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(prID[i+1:])
+	if err != nil || number <= 0 {
+		return "", 0, false
+	}
+	return prID[:i], number, true
+}
+
+func (c *Client) RequestReviewers(ctx context.Context, prID string, logins []string) error {
+	return c.requestedReviewers(ctx, http.MethodPost, prID, logins)
+}
+
+func (c *Client) RemoveReviewers(ctx context.Context, prID string, logins []string) error {
+	return c.requestedReviewers(ctx, http.MethodDelete, prID, logins)
+}
+
+// requestedReviewers вызывает /repos/{owner}/{repo}/pulls/{number}/requested_reviewers
+func (c *Client) requestedReviewers(ctx context.Context, method, prID string, logins []string) error {
+	fullName, number, ok := ParsePRID(prID)
+	if !ok {
+		return fmt.Errorf("%w: %s", integration.ErrInvalidPRID, prID)
+	}
+	body, err := json.Marshal(map[string][]string{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", c.BaseURL, fullName, number)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return integration.CheckResponse(resp)
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/integration"
+)
+
+func TestParsePRID(t *testing.T) {
+	tests := []struct {
+		id       string
+		wantName string
+		wantNum  int
+		wantOK   bool
+	}{
+		{"acme/api#42", "acme/api", 42, true},
+		{PRID("acme/api", 7), "acme/api", 7, true},
+		{"pr-1", "", 0, false},
+		{"api#42", "", 0, false},
+		{"acme/api#x", "", 0, false},
+		{"acme/api#0", "", 0, false},
+	}
+	for _, tt := range tests {
+		name, num, ok := ParsePRID(tt.id)
+		if name != tt.wantName || num != tt.wantNum || ok != tt.wantOK {
+			t.Fatalf("%q: expected (%q, %d, %v), got (%q, %d, %v)", tt.id, tt.wantName, tt.wantNum, tt.wantOK, name, num, ok)
+		}
+	}
+}
+
+func TestClient_RequestedReviewers(t *testing.T) {
+	type request struct {
+		method, path, auth string
+		reviewers          []string
+	}
+	var got []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		got = append(got, request{r.Method, r.URL.Path, r.Header.Get("Authorization"), body.Reviewers})
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL+"/", "ghp_test")
+	if err := c.RequestReviewers(context.Background(), "acme/api#42", []string{"bob"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := c.RemoveReviewers(context.Background(), "acme/api#42", []string{"alice"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := []request{
+		{http.MethodPost, "/repos/acme/api/pulls/42/requested_reviewers", "Bearer ghp_test", []string{"bob"}},
+		{http.MethodDelete, "/repos/acme/api/pulls/42/requested_reviewers", "Bearer ghp_test", []string{"alice"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message":"Reviews may only be requested from collaborators."}`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "ghp_test")
+
+	err := c.RequestReviewers(context.Background(), "acme/api#42", []string{"mallory"})
+	var apiErr *integration.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnprocessableEntity || apiErr.Temporary() {
+		t.Fatalf("expected permanent APIError 422, got %v", err)
+	}
+
+	err = c.RequestReviewers(context.Background(), "pr-1", []string{"bob"})
+	if !errors.Is(err, integration.ErrInvalidPRID) {
+		t.Fatalf("expected ErrInvalidPRID, got %v", err)
+	}
+}
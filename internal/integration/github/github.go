@@ -0,0 +1,115 @@
+// Package github принимает вебхуки GitHub о pull request и передаёт их в integration.Service:
+// открытый PR создаётся с автоматическим назначением ревьюверов, закрытие, merge,
+// повторное открытие и перевод в черновик и обратно меняют статус PR.
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/integration"
+	"github.com/you/pr-assign-avito/internal/webhook"
+)
+
+// Заголовки входящих вебхуков GitHub
+const (
+	HeaderEvent     = "X-GitHub-Event"
+	HeaderSignature = "X-Hub-Signature-256"
+	HeaderDelivery  = "X-GitHub-Delivery"
+)
+
+// PullRequestEvent - нужная сервису часть события pull_request
+type PullRequestEvent struct {
+	Action      string      `json:"action"`
+	Number      int         `json:"number"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  Repository  `json:"repository"`
+	Sender      Account     `json:"sender"`
+}
+
+type PullRequest struct {
+	Number   int      `json:"number"`
+	Title    string   `json:"title"`
+	Draft    bool     `json:"draft"`
+	Merged   bool     `json:"merged"`
+	User     Account  `json:"user"`
+	MergedBy *Account `json:"merged_by"`
+}
+
+type Repository struct {
+	FullName string `json:"full_name"`
+}
+
+type Account struct {
+	Login string `json:"login"`
+}
+
+// VerifySignature проверяет заголовок X-Hub-Signature-256: HMAC-SHA256 тела с секретом вебхука.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	return hmac.Equal([]byte(webhook.Sign(secret, body)), []byte(signature))
+}
+
+// PRID возвращает идентификатор PR сервиса для PR number репозитория fullName.
+func PRID(fullName string, number int) string {
+	return fmt.Sprintf("%s#%d", fullName, number)
+}
+
+// Ingestor переводит события GitHub в операции с PR.
+type Ingestor struct {
+	Service *integration.Service
+}
+
+func NewIngestor(prs integration.PRService, accounts integration.AccountStore) *Ingestor {
+	return &Ingestor{Service: integration.NewService(domain.ProviderGitHub, prs, accounts)}
+}
+
+// Handle обрабатывает событие eventType с телом body. События, которые сервис не
+// отслеживает, и повторные доставки уже обработанных событий игнорируются.
+func (in *Ingestor) Handle(ctx context.Context, eventType string, body []byte) (integration.Result, error) {
+	if eventType != "pull_request" {
+		return integration.Ignored(""), nil
+	}
+	var e PullRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return integration.Result{}, fmt.Errorf("decode pull_request event: %w", err)
+	}
+	if e.Repository.FullName == "" || e.PullRequest.Number == 0 {
+		return integration.Result{}, errors.New("pull_request event without repository or number")
+	}
+	prID := PRID(e.Repository.FullName, e.PullRequest.Number)
+	ctx = domain.WithActor(ctx, domain.ProviderGitHub+":"+e.Sender.Login)
+
+	switch e.Action {
+	case "opened":
+		return in.Service.Open(ctx, integration.OpenedPR{
+			ID:          prID,
+			Title:       e.PullRequest.Title,
+			AuthorLogin: e.PullRequest.User.Login,
+			Draft:       e.PullRequest.Draft,
+		})
+	case "closed":
+		if e.PullRequest.Merged {
+			merger := e.Sender
+			if e.PullRequest.MergedBy != nil {
+				merger = *e.PullRequest.MergedBy
+			}
+			return in.Service.Merge(ctx, prID, merger.Login)
+		}
+		return in.Service.Close(ctx, prID)
+	case "reopened":
+		return in.Service.Reopen(ctx, prID)
+	case "ready_for_review":
+		return in.Service.MarkReady(ctx, prID)
+	case "converted_to_draft":
+		return in.Service.MarkDraft(ctx, prID)
+	}
+	return integration.Ignored(prID), nil
+}
@@ -0,0 +1,239 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/integration"
+	"github.com/you/pr-assign-avito/internal/repository"
+	uc "github.com/you/pr-assign-avito/internal/usecase"
+)
+
+// fakePRs записывает вызовы и возвращает заранее заданные ошибки по имени операции.
+// Для PR, созданных через него, как и usecase, отклоняет недопустимые переходы статуса.
+type fakePRs struct {
+	calls    []string
+	created  []domain.PullRequest
+	forced   string
+	errs     map[string]error
+	statuses map[string]domain.PRStatus
+}
+
+func (f *fakePRs) call(op, prID string) error {
+	f.calls = append(f.calls, op+" "+prID)
+	return f.errs[op]
+}
+
+func (f *fakePRs) move(op, prID string, to domain.PRStatus) error {
+	if err := f.call(op, prID); err != nil {
+		return err
+	}
+	from, ok := f.statuses[prID]
+	if !ok {
+		return nil
+	}
+	if !domain.CanTransition(from, to) {
+		return uc.ErrInvalidTransition
+	}
+	f.statuses[prID] = to
+	return nil
+}
+
+func (f *fakePRs) CreatePR(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	f.created = append(f.created, pr)
+	if err := f.call("create", pr.ID); err != nil {
+		return pr, err
+	}
+	if f.statuses == nil {
+		f.statuses = map[string]domain.PRStatus{}
+	}
+	f.statuses[pr.ID] = domain.StatusOpen
+	if pr.Status == domain.StatusDraft {
+		f.statuses[pr.ID] = domain.StatusDraft
+	}
+	return pr, nil
+}
+
+func (f *fakePRs) MergePR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("merge", prID, domain.StatusMerged)
+}
+
+func (f *fakePRs) ForceMergePR(ctx context.Context, prID, adminID string) (domain.PullRequest, error) {
+	f.forced = adminID
+	return domain.PullRequest{}, f.move("forceMerge", prID, domain.StatusMerged)
+}
+
+func (f *fakePRs) ClosePR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("close", prID, domain.StatusClosed)
+}
+
+func (f *fakePRs) ReopenPR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("reopen", prID, domain.StatusReopened)
+}
+
+func (f *fakePRs) MarkDraft(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("markDraft", prID, domain.StatusDraft)
+}
+
+func (f *fakePRs) MarkReady(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("markReady", prID, domain.StatusOpen)
+}
+
+type fakeAccounts map[string]string
+
+func (a fakeAccounts) GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error) {
+	if id, ok := a[provider+"/"+login]; ok {
+		return id, nil
+	}
+	return "", repository.ErrNotFound
+}
+
+func readPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return body
+}
+
+func TestIngestor_ReplaysRecordedPayloads(t *testing.T) {
+	const prID = "acme/backend#42"
+	tests := []struct {
+		name      string
+		event     string
+		payload   string
+		errs      map[string]error
+		want      integration.Result
+		wantCalls []string
+	}{
+		{"opened", "pull_request", "pull_request_opened.json", nil,
+			integration.Result{Action: integration.ActionCreated, PullRequestID: prID}, []string{"create " + prID}},
+		{"redelivered opened", "pull_request", "pull_request_opened.json", map[string]error{"create": uc.ErrPRExists},
+			integration.Result{Action: integration.ActionIgnored, PullRequestID: prID}, []string{"create " + prID}},
+		{"merged", "pull_request", "pull_request_closed_merged.json", nil,
+			integration.Result{Action: integration.ActionMerged, PullRequestID: prID}, []string{"merge " + prID}},
+		{"merged against policy", "pull_request", "pull_request_closed_merged.json", map[string]error{"merge": &uc.MergeBlockedError{Unmet: []string{"required 2 approvals, got 0"}}},
+			integration.Result{Action: integration.ActionMerged, PullRequestID: prID}, []string{"merge " + prID, "forceMerge " + prID}},
+		{"closed", "pull_request", "pull_request_closed.json", nil,
+			integration.Result{Action: integration.ActionClosed, PullRequestID: prID}, []string{"close " + prID}},
+		{"closed untracked", "pull_request", "pull_request_closed.json", map[string]error{"close": uc.ErrNotFound},
+			integration.Result{Action: integration.ActionIgnored, PullRequestID: prID}, []string{"close " + prID}},
+		{"reopened", "pull_request", "pull_request_reopened.json", nil,
+			integration.Result{Action: integration.ActionReopened, PullRequestID: prID}, []string{"reopen " + prID}},
+		{"ready for review", "pull_request", "pull_request_ready_for_review.json", nil,
+			integration.Result{Action: integration.ActionReady, PullRequestID: prID}, []string{"markReady " + prID}},
+		{"converted to draft", "pull_request", "pull_request_converted_to_draft.json", nil,
+			integration.Result{Action: integration.ActionDrafted, PullRequestID: prID}, []string{"markDraft " + prID}},
+		{"other action", "pull_request", "pull_request_labeled.json", nil,
+			integration.Result{Action: integration.ActionIgnored, PullRequestID: prID}, nil},
+		{"ping", "ping", "ping.json", nil,
+			integration.Ignored(""), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := &fakePRs{errs: tt.errs}
+			in := NewIngestor(prs, fakeAccounts{"github/Alice-Dev": "u1"})
+
+			got, err := in.Handle(context.Background(), tt.event, readPayload(t, tt.payload))
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+			if !reflect.DeepEqual(prs.calls, tt.wantCalls) {
+				t.Fatalf("expected calls %v, got %v", tt.wantCalls, prs.calls)
+			}
+		})
+	}
+}
+
+func TestIngestor_OpenedMapsAuthorAndDraft(t *testing.T) {
+	prs := &fakePRs{}
+	in := NewIngestor(prs, fakeAccounts{"github/Alice-Dev": "u1"})
+
+	if _, err := in.Handle(context.Background(), "pull_request", readPayload(t, "pull_request_opened_draft.json")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := domain.PullRequest{ID: "acme/backend#42", Title: "WIP: Add rate limiter to public API", AuthorID: "u1", Status: domain.StatusDraft, Source: domain.ProviderGitHub}
+	if len(prs.created) != 1 || !reflect.DeepEqual(prs.created[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, prs.created)
+	}
+}
+
+func TestIngestor_DraftReadyThenMerged(t *testing.T) {
+	const prID = "acme/backend#42"
+	prs := &fakePRs{}
+	in := NewIngestor(prs, fakeAccounts{"github/Alice-Dev": "u1"})
+
+	for _, payload := range []string{"pull_request_opened_draft.json", "pull_request_ready_for_review.json", "pull_request_closed_merged.json"} {
+		if _, err := in.Handle(context.Background(), "pull_request", readPayload(t, payload)); err != nil {
+			t.Fatalf("%s: unexpected err: %v", payload, err)
+		}
+	}
+	if prs.statuses[prID] != domain.StatusMerged {
+		t.Fatalf("expected PR merged, got %s", prs.statuses[prID])
+	}
+	want := []string{"create " + prID, "markReady " + prID, "merge " + prID}
+	if !reflect.DeepEqual(prs.calls, want) {
+		t.Fatalf("expected calls %v, got %v", want, prs.calls)
+	}
+}
+
+func TestIngestor_ForcedMergeRecordsGitHubLogin(t *testing.T) {
+	prs := &fakePRs{errs: map[string]error{"merge": &uc.MergeBlockedError{}}}
+	in := NewIngestor(prs, fakeAccounts{})
+
+	if _, err := in.Handle(context.Background(), "pull_request", readPayload(t, "pull_request_closed_merged.json")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if prs.forced != "github:bob-lead" {
+		t.Fatalf("expected merge forced by github:bob-lead, got %q", prs.forced)
+	}
+}
+
+func TestIngestor_UnknownLogin(t *testing.T) {
+	prs := &fakePRs{}
+	in := NewIngestor(prs, fakeAccounts{})
+
+	_, err := in.Handle(context.Background(), "pull_request", readPayload(t, "pull_request_opened.json"))
+	if !errors.Is(err, integration.ErrUnknownLogin) {
+		t.Fatalf("expected ErrUnknownLogin, got %v", err)
+	}
+	if len(prs.calls) != 0 {
+		t.Fatalf("expected no PR created, got %v", prs.calls)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	// пример из документации GitHub о проверке доставок вебхуков
+	const secret = "It's a Secret to Everybody"
+	body := []byte("Hello, World!")
+	const valid = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{"valid", secret, valid, true},
+		{"wrong secret", "other", valid, false},
+		{"missing", secret, "", false},
+		{"sha1 only", secret, "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59", false},
+		{"no secret configured", "", valid, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, body, tt.signature); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/integration"
+)
+
+// DefaultAPIURL - REST API gitlab.com; для своей инсталляции - https://<host>/api/v4
+const DefaultAPIURL = "https://gitlab.com/api/v4"
+
+// reviewerUpdateAttempts - сколько раз клиент пересчитывает список ревьюверов MR,
+// если тот меняется, пока клиент готовит запись
+const reviewerUpdateAttempts = 3
+
+// Client назначает ревьюверов MR через REST API GitLab. API принимает только полный
+// список reviewer_ids, поэтому клиент читает текущих ревьюверов MR и меняет список.
+// Перед записью список перечитывается и при расхождении пересчитывается заново; у API
+// нет условной записи, поэтому изменение, сделанное между последним чтением и PUT,
+// всё же может быть затёрто.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ParseMRID разбирает идентификатор, построенный MRID.
+func ParseMRID(prID string) (path string, iid int, ok bool) {
+	i := strings.LastIndex(prID, "!")
+	if i <= 0 || !strings.Contains(prID[:i], "/") {
+		return "", 0, false
+	}
+	iid, err := strconv.Atoi(prID[i+1:])
+	if err != nil || iid <= 0 {
+		return "", 0, false
+	}
+	return prID[:i], iid, true
+}
+
+type apiUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+func (c *Client) RequestReviewers(ctx context.Context, prID string, logins []string) error {
+	return c.updateReviewers(ctx, prID, logins, nil)
+}
+
+func (c *Client) RemoveReviewers(ctx context.Context, prID string, logins []string) error {
+	return c.updateReviewers(ctx, prID, nil, logins)
+}
+
+func (c *Client) updateReviewers(ctx context.Context, prID string, add, remove []string) error {
+	path, iid, ok := ParseMRID(prID)
+	if !ok {
+		return fmt.Errorf("%w: %s", integration.ErrInvalidPRID, prID)
+	}
+	mrURL := fmt.Sprintf("%s/projects/%s/merge_requests/%d", c.BaseURL, url.PathEscape(path), iid)
+
+	addIDs := make([]int, 0, len(add))
+	for _, login := range add {
+		id, err := c.userID(ctx, login)
+		if err != nil {
+			return err
+		}
+		addIDs = append(addIDs, id)
+	}
+	removed := map[string]bool{}
+	for _, login := range remove {
+		removed[strings.ToLower(login)] = true
+	}
+
+	current, err := c.reviewers(ctx, mrURL)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		ids := []int{}
+		present := map[int]bool{}
+		for _, r := range current {
+			if removed[strings.ToLower(r.Username)] {
+				continue
+			}
+			ids = append(ids, r.ID)
+			present[r.ID] = true
+		}
+		for _, id := range addIDs {
+			if !present[id] {
+				ids = append(ids, id)
+				present[id] = true
+			}
+		}
+		// список могли поменять, пока он пересчитывался (в GitLab или другим событием):
+		// запись по устаревшему списку затёрла бы это изменение
+		latest, err := c.reviewers(ctx, mrURL)
+		if err != nil {
+			return err
+		}
+		if sameReviewers(current, latest) {
+			return c.do(ctx, http.MethodPut, mrURL, map[string][]int{"reviewer_ids": ids}, nil)
+		}
+		if attempt == reviewerUpdateAttempts {
+			return fmt.Errorf("gitlab: reviewers of %s changed concurrently %d times", prID, attempt)
+		}
+		current = latest
+	}
+}
+
+// reviewers читает текущих ревьюверов MR
+func (c *Client) reviewers(ctx context.Context, mrURL string) ([]apiUser, error) {
+	var mr struct {
+		Reviewers []apiUser `json:"reviewers"`
+	}
+	if err := c.do(ctx, http.MethodGet, mrURL, nil, &mr); err != nil {
+		return nil, err
+	}
+	return mr.Reviewers, nil
+}
+
+func sameReviewers(a, b []apiUser) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := map[int]bool{}
+	for _, r := range a {
+		ids[r.ID] = true
+	}
+	for _, r := range b {
+		if !ids[r.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// userID находит id пользователя GitLab по username
+func (c *Client) userID(ctx context.Context, username string) (int, error) {
+	var users []apiUser
+	if err := c.do(ctx, http.MethodGet, c.BaseURL+"/users?username="+url.QueryEscape(username), nil, &users); err != nil {
+		return 0, err
+	}
+	if len(users) == 0 {
+		return 0, &integration.APIError{Status: http.StatusNotFound, Body: "user " + username + " not found"}
+	}
+	return users[0].ID, nil
+}
+
+func (c *Client) do(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := integration.CheckResponse(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,163 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/integration"
+)
+
+// fakeGitLab - минимальный API GitLab: пользователи и ревьюверы одного MR
+type fakeGitLab struct {
+	users     map[string]int
+	reviewers []apiUser
+	puts      [][]int
+	tokens    []string
+	// onMRGet вызывается после каждого чтения MR - имитирует правку ревьюверов в GitLab
+	onMRGet func(f *fakeGitLab)
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.tokens = append(f.tokens, r.Header.Get("PRIVATE-TOKEN"))
+	const mrPath = "/api/v4/projects/payments%2Fbilling/merge_requests/17"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v4/users":
+		users := []apiUser{}
+		if id, ok := f.users[r.URL.Query().Get("username")]; ok {
+			users = append(users, apiUser{ID: id, Username: r.URL.Query().Get("username")})
+		}
+		_ = json.NewEncoder(w).Encode(users)
+	case r.Method == http.MethodGet && r.URL.EscapedPath() == mrPath:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"reviewers": f.reviewers})
+		if f.onMRGet != nil {
+			f.onMRGet(f)
+		}
+	case r.Method == http.MethodPut && r.URL.EscapedPath() == mrPath:
+		var body struct {
+			ReviewerIDs []int `json:"reviewer_ids"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.puts = append(f.puts, body.ReviewerIDs)
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestParseMRID(t *testing.T) {
+	tests := []struct {
+		id       string
+		wantPath string
+		wantIID  int
+		wantOK   bool
+	}{
+		{"payments/billing!17", "payments/billing", 17, true},
+		{MRID("group/sub/project", 3), "group/sub/project", 3, true},
+		{"acme/api#42", "", 0, false},
+		{"billing!17", "", 0, false},
+	}
+	for _, tt := range tests {
+		path, iid, ok := ParseMRID(tt.id)
+		if path != tt.wantPath || iid != tt.wantIID || ok != tt.wantOK {
+			t.Fatalf("%q: expected (%q, %d, %v), got (%q, %d, %v)", tt.id, tt.wantPath, tt.wantIID, tt.wantOK, path, iid, ok)
+		}
+	}
+}
+
+func TestClient_UpdatesReviewerIDs(t *testing.T) {
+	fake := &fakeGitLab{
+		users:     map[string]int{"dpetrov": 12, "carol.i": 7},
+		reviewers: []apiUser{{ID: 7, Username: "carol.i"}, {ID: 31, Username: "anna"}},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v4", "glpat-test")
+
+	if err := c.RequestReviewers(context.Background(), "payments/billing!17", []string{"dpetrov"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := c.RequestReviewers(context.Background(), "payments/billing!17", []string{"carol.i"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := c.RemoveReviewers(context.Background(), "payments/billing!17", []string{"Carol.I"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := [][]int{{7, 31, 12}, {7, 31}, {31}}
+	if !reflect.DeepEqual(fake.puts, want) {
+		t.Fatalf("expected reviewer_ids %v, got %v", want, fake.puts)
+	}
+	for _, tok := range fake.tokens {
+		if tok != "glpat-test" {
+			t.Fatalf("expected PRIVATE-TOKEN on every request, got %q", tok)
+		}
+	}
+}
+
+func TestClient_UnknownUsername(t *testing.T) {
+	fake := &fakeGitLab{users: map[string]int{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v4", "glpat-test")
+
+	err := c.RequestReviewers(context.Background(), "payments/billing!17", []string{"ghost"})
+	var apiErr *integration.APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || apiErr.Temporary() {
+		t.Fatalf("expected permanent APIError 404, got %v", err)
+	}
+	if len(fake.puts) != 0 {
+		t.Fatalf("expected no update, got %v", fake.puts)
+	}
+}
+
+func TestClient_RereadsReviewersChangedBeforeWrite(t *testing.T) {
+	gets := 0
+	fake := &fakeGitLab{
+		users:     map[string]int{"dpetrov": 12},
+		reviewers: []apiUser{{ID: 31, Username: "anna"}},
+		// сразу после первого чтения в GitLab вручную добавляют ревьювера
+		onMRGet: func(f *fakeGitLab) {
+			gets++
+			if gets == 1 {
+				f.reviewers = append(f.reviewers, apiUser{ID: 7, Username: "carol.i"})
+			}
+		},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v4", "glpat-test")
+
+	if err := c.RequestReviewers(context.Background(), "payments/billing!17", []string{"dpetrov"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := [][]int{{31, 7, 12}}
+	if !reflect.DeepEqual(fake.puts, want) {
+		t.Fatalf("expected reviewer_ids %v, got %v", want, fake.puts)
+	}
+}
+
+func TestClient_GivesUpWhenReviewersKeepChanging(t *testing.T) {
+	next := 100
+	fake := &fakeGitLab{
+		users: map[string]int{"dpetrov": 12},
+		onMRGet: func(f *fakeGitLab) {
+			next++
+			f.reviewers = append(f.reviewers, apiUser{ID: next, Username: "bot"})
+		},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	c := NewClient(srv.URL+"/api/v4", "glpat-test")
+
+	if err := c.RequestReviewers(context.Background(), "payments/billing!17", []string{"dpetrov"}); err == nil {
+		t.Fatalf("expected error when reviewers keep changing")
+	}
+	if len(fake.puts) != 0 {
+		t.Fatalf("expected no update, got %v", fake.puts)
+	}
+}
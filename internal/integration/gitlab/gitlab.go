@@ -0,0 +1,131 @@
+// Package gitlab принимает вебхуки GitLab о merge request и передаёт их в integration.Service.
+package gitlab
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/integration"
+)
+
+// Заголовки входящих вебхуков GitLab
+const (
+	HeaderEvent     = "X-Gitlab-Event"
+	HeaderToken     = "X-Gitlab-Token"
+	HeaderEventUUID = "X-Gitlab-Event-UUID"
+)
+
+// EventMergeRequest - значение X-Gitlab-Event для событий merge request
+const EventMergeRequest = "Merge Request Hook"
+
+// MergeRequestEvent - нужная сервису часть события merge_request.
+// User - тот, кто выполнил действие: для open это автор MR, для merge - кто смержил.
+type MergeRequestEvent struct {
+	ObjectKind       string           `json:"object_kind"`
+	User             User             `json:"user"`
+	Project          Project          `json:"project"`
+	ObjectAttributes ObjectAttributes `json:"object_attributes"`
+	Changes          Changes          `json:"changes"`
+}
+
+type User struct {
+	Username string `json:"username"`
+}
+
+type Project struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+type ObjectAttributes struct {
+	IID    int    `json:"iid"`
+	Title  string `json:"title"`
+	Action string `json:"action"`
+	Draft  bool   `json:"draft"`
+	// WorkInProgress - прежнее название Draft в старых версиях GitLab
+	WorkInProgress bool `json:"work_in_progress"`
+}
+
+// Changes - изменённые действием update атрибуты MR, которые отслеживает сервис
+type Changes struct {
+	Draft          *BoolChange `json:"draft"`
+	WorkInProgress *BoolChange `json:"work_in_progress"`
+}
+
+type BoolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+// VerifyToken сравнивает X-Gitlab-Token с секретным токеном вебхука.
+func VerifyToken(secret, token string) bool {
+	if secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+// MRID возвращает идентификатор PR сервиса для MR iid проекта path.
+func MRID(path string, iid int) string {
+	return fmt.Sprintf("%s!%d", path, iid)
+}
+
+// Ingestor переводит события GitLab в операции с PR.
+type Ingestor struct {
+	Service *integration.Service
+}
+
+func NewIngestor(prs integration.PRService, accounts integration.AccountStore) *Ingestor {
+	return &Ingestor{Service: integration.NewService(domain.ProviderGitLab, prs, accounts)}
+}
+
+// Handle обрабатывает событие eventType с телом body. События, которые сервис не
+// отслеживает, и повторные доставки уже обработанных событий игнорируются.
+func (in *Ingestor) Handle(ctx context.Context, eventType string, body []byte) (integration.Result, error) {
+	if eventType != EventMergeRequest {
+		return integration.Ignored(""), nil
+	}
+	var e MergeRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return integration.Result{}, fmt.Errorf("decode merge_request event: %w", err)
+	}
+	attrs := e.ObjectAttributes
+	if e.Project.PathWithNamespace == "" || attrs.IID == 0 {
+		return integration.Result{}, errors.New("merge_request event without project or iid")
+	}
+	prID := MRID(e.Project.PathWithNamespace, attrs.IID)
+	ctx = domain.WithActor(ctx, domain.ProviderGitLab+":"+e.User.Username)
+
+	switch attrs.Action {
+	case "open":
+		return in.Service.Open(ctx, integration.OpenedPR{
+			ID:          prID,
+			Title:       attrs.Title,
+			AuthorLogin: e.User.Username,
+			Draft:       attrs.Draft || attrs.WorkInProgress,
+		})
+	case "merge":
+		return in.Service.Merge(ctx, prID, e.User.Username)
+	case "close":
+		return in.Service.Close(ctx, prID)
+	case "reopen":
+		return in.Service.Reopen(ctx, prID)
+	case "update":
+		// update приходит на любое изменение MR, статус меняет только смена флага draft
+		change := e.Changes.Draft
+		if change == nil {
+			change = e.Changes.WorkInProgress
+		}
+		if change == nil || change.Previous == change.Current {
+			break
+		}
+		if change.Current {
+			return in.Service.MarkDraft(ctx, prID)
+		}
+		return in.Service.MarkReady(ctx, prID)
+	}
+	return integration.Ignored(prID), nil
+}
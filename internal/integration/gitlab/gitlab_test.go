@@ -0,0 +1,236 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/integration"
+	"github.com/you/pr-assign-avito/internal/repository"
+	uc "github.com/you/pr-assign-avito/internal/usecase"
+)
+
+// fakePRs записывает вызовы и возвращает заранее заданные ошибки по имени операции.
+// Для PR, созданных через него, как и usecase, отклоняет недопустимые переходы статуса.
+type fakePRs struct {
+	calls    []string
+	created  []domain.PullRequest
+	forced   string
+	errs     map[string]error
+	statuses map[string]domain.PRStatus
+}
+
+func (f *fakePRs) call(op, prID string) error {
+	f.calls = append(f.calls, op+" "+prID)
+	return f.errs[op]
+}
+
+func (f *fakePRs) move(op, prID string, to domain.PRStatus) error {
+	if err := f.call(op, prID); err != nil {
+		return err
+	}
+	from, ok := f.statuses[prID]
+	if !ok {
+		return nil
+	}
+	if !domain.CanTransition(from, to) {
+		return uc.ErrInvalidTransition
+	}
+	f.statuses[prID] = to
+	return nil
+}
+
+func (f *fakePRs) CreatePR(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
+	f.created = append(f.created, pr)
+	if err := f.call("create", pr.ID); err != nil {
+		return pr, err
+	}
+	if f.statuses == nil {
+		f.statuses = map[string]domain.PRStatus{}
+	}
+	f.statuses[pr.ID] = domain.StatusOpen
+	if pr.Status == domain.StatusDraft {
+		f.statuses[pr.ID] = domain.StatusDraft
+	}
+	return pr, nil
+}
+
+func (f *fakePRs) MergePR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("merge", prID, domain.StatusMerged)
+}
+
+func (f *fakePRs) ForceMergePR(ctx context.Context, prID, adminID string) (domain.PullRequest, error) {
+	f.forced = adminID
+	return domain.PullRequest{}, f.move("forceMerge", prID, domain.StatusMerged)
+}
+
+func (f *fakePRs) ClosePR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("close", prID, domain.StatusClosed)
+}
+
+func (f *fakePRs) ReopenPR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("reopen", prID, domain.StatusReopened)
+}
+
+func (f *fakePRs) MarkDraft(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("markDraft", prID, domain.StatusDraft)
+}
+
+func (f *fakePRs) MarkReady(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return domain.PullRequest{}, f.move("markReady", prID, domain.StatusOpen)
+}
+
+type fakeAccounts map[string]string
+
+func (a fakeAccounts) GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error) {
+	if id, ok := a[provider+"/"+login]; ok {
+		return id, nil
+	}
+	return "", repository.ErrNotFound
+}
+
+func readPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return body
+}
+
+func TestIngestor_ReplaysRecordedPayloads(t *testing.T) {
+	const prID = "payments/billing!17"
+	tests := []struct {
+		name      string
+		event     string
+		payload   string
+		errs      map[string]error
+		want      string
+		wantCalls []string
+	}{
+		{"open", EventMergeRequest, "merge_request_open.json", nil,
+			integration.ActionCreated, []string{"create " + prID}},
+		{"redelivered open", EventMergeRequest, "merge_request_open.json", map[string]error{"create": uc.ErrPRExists},
+			integration.ActionIgnored, []string{"create " + prID}},
+		{"merge", EventMergeRequest, "merge_request_merge.json", nil,
+			integration.ActionMerged, []string{"merge " + prID}},
+		{"merge against policy", EventMergeRequest, "merge_request_merge.json", map[string]error{"merge": &uc.MergeBlockedError{}},
+			integration.ActionMerged, []string{"merge " + prID, "forceMerge " + prID}},
+		{"close", EventMergeRequest, "merge_request_close.json", nil,
+			integration.ActionClosed, []string{"close " + prID}},
+		{"reopen", EventMergeRequest, "merge_request_reopen.json", nil,
+			integration.ActionReopened, []string{"reopen " + prID}},
+		{"reopen untracked", EventMergeRequest, "merge_request_reopen.json", map[string]error{"reopen": uc.ErrNotFound},
+			integration.ActionIgnored, []string{"reopen " + prID}},
+		{"update ready", EventMergeRequest, "merge_request_update_ready.json", nil,
+			integration.ActionReady, []string{"markReady " + prID}},
+		{"update draft", EventMergeRequest, "merge_request_update_draft.json", nil,
+			integration.ActionDrafted, []string{"markDraft " + prID}},
+		{"update title", EventMergeRequest, "merge_request_update_title.json", nil,
+			integration.ActionIgnored, nil},
+		{"other action", EventMergeRequest, "merge_request_approved.json", nil,
+			integration.ActionIgnored, nil},
+		{"push", "Push Hook", "push.json", nil,
+			integration.ActionIgnored, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := &fakePRs{errs: tt.errs}
+			in := NewIngestor(prs, fakeAccounts{"gitlab/carol.i": "u1"})
+
+			got, err := in.Handle(context.Background(), tt.event, readPayload(t, tt.payload))
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got.Action != tt.want {
+				t.Fatalf("expected action %s, got %+v", tt.want, got)
+			}
+			if !reflect.DeepEqual(prs.calls, tt.wantCalls) {
+				t.Fatalf("expected calls %v, got %v", tt.wantCalls, prs.calls)
+			}
+		})
+	}
+}
+
+func TestIngestor_OpenMapsAuthorAndDraft(t *testing.T) {
+	prs := &fakePRs{}
+	in := NewIngestor(prs, fakeAccounts{"gitlab/carol.i": "u1"})
+
+	if _, err := in.Handle(context.Background(), EventMergeRequest, readPayload(t, "merge_request_open_draft.json")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := domain.PullRequest{ID: "payments/billing!17", Title: "Draft: Retry failed invoice webhooks", AuthorID: "u1", Status: domain.StatusDraft, Source: domain.ProviderGitLab}
+	if len(prs.created) != 1 || !reflect.DeepEqual(prs.created[0], want) {
+		t.Fatalf("expected %+v, got %+v", want, prs.created)
+	}
+}
+
+func TestIngestor_DraftReadyThenMerged(t *testing.T) {
+	const prID = "payments/billing!17"
+	prs := &fakePRs{}
+	in := NewIngestor(prs, fakeAccounts{"gitlab/carol.i": "u1"})
+
+	for _, payload := range []string{"merge_request_open_draft.json", "merge_request_update_ready.json", "merge_request_merge.json"} {
+		if _, err := in.Handle(context.Background(), EventMergeRequest, readPayload(t, payload)); err != nil {
+			t.Fatalf("%s: unexpected err: %v", payload, err)
+		}
+	}
+	if prs.statuses[prID] != domain.StatusMerged {
+		t.Fatalf("expected MR merged, got %s", prs.statuses[prID])
+	}
+	want := []string{"create " + prID, "markReady " + prID, "merge " + prID}
+	if !reflect.DeepEqual(prs.calls, want) {
+		t.Fatalf("expected calls %v, got %v", want, prs.calls)
+	}
+}
+
+func TestIngestor_ForcedMergeRecordsGitLabUsername(t *testing.T) {
+	prs := &fakePRs{errs: map[string]error{"merge": &uc.MergeBlockedError{}}}
+	in := NewIngestor(prs, fakeAccounts{})
+
+	if _, err := in.Handle(context.Background(), EventMergeRequest, readPayload(t, "merge_request_merge.json")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if prs.forced != "gitlab:dpetrov" {
+		t.Fatalf("expected merge forced by gitlab:dpetrov, got %q", prs.forced)
+	}
+}
+
+func TestIngestor_UnknownUsername(t *testing.T) {
+	prs := &fakePRs{}
+	in := NewIngestor(prs, fakeAccounts{"github/carol.i": "u1"})
+
+	_, err := in.Handle(context.Background(), EventMergeRequest, readPayload(t, "merge_request_open.json"))
+	if !errors.Is(err, integration.ErrUnknownLogin) {
+		t.Fatalf("expected ErrUnknownLogin, got %v", err)
+	}
+	if len(prs.calls) != 0 {
+		t.Fatalf("expected no PR created, got %v", prs.calls)
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		want   bool
+	}{
+		{"valid", "s3cret", "s3cret", true},
+		{"wrong", "s3cret", "other", false},
+		{"missing", "s3cret", "", false},
+		{"no secret configured", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyToken(tt.secret, tt.token); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Package integration отражает в сервисе жизненный цикл PR из внешних систем
+// (GitHub, GitLab) и назначения ревьюверов в них. Пакеты конкретных систем разбирают
+// их вебхуки, вызывая Service, и реализуют CodeHostClient.
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+	uc "github.com/you/pr-assign-avito/internal/usecase"
+)
+
+// Результаты обработки события
+const (
+	ActionCreated  = "created"
+	ActionMerged   = "merged"
+	ActionClosed   = "closed"
+	ActionReopened = "reopened"
+	ActionReady    = "ready"
+	ActionDrafted  = "drafted"
+	ActionIgnored  = "ignored"
+)
+
+// ErrUnknownLogin - логин внешней системы не связан ни с одним пользователем сервиса
+var ErrUnknownLogin = errors.New("unknown login")
+
+// PRService - операции с PR, которые вызывают вебхуки (реализуется usecase.PRUsecase)
+type PRService interface {
+	CreatePR(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error)
+	MergePR(ctx context.Context, prID string) (domain.PullRequest, error)
+	ForceMergePR(ctx context.Context, prID, adminID string) (domain.PullRequest, error)
+	ClosePR(ctx context.Context, prID string) (domain.PullRequest, error)
+	ReopenPR(ctx context.Context, prID string) (domain.PullRequest, error)
+	MarkDraft(ctx context.Context, prID string) (domain.PullRequest, error)
+	MarkReady(ctx context.Context, prID string) (domain.PullRequest, error)
+}
+
+// AccountStore - соответствие логинов внешних систем пользователям (реализуется repository.Repo)
+type AccountStore interface {
+	GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error)
+}
+
+// Result - что сделано по событию
+type Result struct {
+	Action        string `json:"action"`
+	PullRequestID string `json:"pull_request_id,omitempty"`
+}
+
+// Ignored - результат для событий, которые сервис не отслеживает
+func Ignored(prID string) Result {
+	return Result{Action: ActionIgnored, PullRequestID: prID}
+}
+
+// OpenedPR - PR, открытый во внешней системе
+type OpenedPR struct {
+	ID          string
+	Title       string
+	AuthorLogin string
+	Draft       bool
+}
+
+// Service выполняет операции с PR от имени внешней системы Provider.
+type Service struct {
+	Provider string
+	PRs      PRService
+	Accounts AccountStore
+}
+
+func NewService(provider string, prs PRService, accounts AccountStore) *Service {
+	return &Service{Provider: provider, PRs: prs, Accounts: accounts}
+}
+
+// Open создаёт PR с автоматическим назначением ревьюверов. Повторная доставка
+// того же события игнорируется.
+func (s *Service) Open(ctx context.Context, pr OpenedPR) (Result, error) {
+	authorID, err := s.Accounts.GetUserIDByExternalLogin(ctx, s.Provider, pr.AuthorLogin)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return Result{}, fmt.Errorf("%w: %s/%s", ErrUnknownLogin, s.Provider, pr.AuthorLogin)
+		}
+		return Result{}, err
+	}
+	created := domain.PullRequest{ID: pr.ID, Title: pr.Title, AuthorID: authorID, Source: s.Provider}
+	if pr.Draft {
+		created.Status = domain.StatusDraft
+	}
+	_, err = s.PRs.CreatePR(ctx, created)
+	if errors.Is(err, uc.ErrPRExists) {
+		return Ignored(pr.ID), nil
+	}
+	return result(ActionCreated, pr.ID, err)
+}
+
+// Merge отражает уже состоявшийся во внешней системе merge. Политика merge сервиса
+// его не отменит, поэтому при невыполненной политике merge записывается как
+// принудительный от имени `<provider>:<логин того, кто смержил>`.
+func (s *Service) Merge(ctx context.Context, prID, mergerLogin string) (Result, error) {
+	_, err := s.PRs.MergePR(ctx, prID)
+	if errors.Is(err, uc.ErrMergeBlocked) {
+		_, err = s.PRs.ForceMergePR(ctx, prID, s.Provider+":"+mergerLogin)
+	}
+	return result(ActionMerged, prID, err)
+}
+
+func (s *Service) Close(ctx context.Context, prID string) (Result, error) {
+	_, err := s.PRs.ClosePR(ctx, prID)
+	return result(ActionClosed, prID, err)
+}
+
+func (s *Service) Reopen(ctx context.Context, prID string) (Result, error) {
+	_, err := s.PRs.ReopenPR(ctx, prID)
+	return result(ActionReopened, prID, err)
+}
+
+// MarkReady отражает перевод черновика в готовые к ревью, без него merge черновика недопустим.
+func (s *Service) MarkReady(ctx context.Context, prID string) (Result, error) {
+	_, err := s.PRs.MarkReady(ctx, prID)
+	return result(ActionReady, prID, err)
+}
+
+func (s *Service) MarkDraft(ctx context.Context, prID string) (Result, error) {
+	_, err := s.PRs.MarkDraft(ctx, prID)
+	return result(ActionDrafted, prID, err)
+}
+
+// result игнорирует события о PR, созданных до подключения интеграции
+func result(action, prID string, err error) (Result, error) {
+	if errors.Is(err, uc.ErrNotFound) {
+		return Ignored(prID), nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Action: action, PullRequestID: prID}, nil
+}
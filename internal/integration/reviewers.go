@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// ErrInvalidPRID - идентификатор PR не соответствует формату внешней системы
+var ErrInvalidPRID = errors.New("invalid external pr id")
+
+// CodeHostClient запрашивает ревью на PR во внешней системе. prID - идентификатор
+// PR сервиса, logins - логины пользователей во внешней системе.
+type CodeHostClient interface {
+	RequestReviewers(ctx context.Context, prID string, logins []string) error
+	RemoveReviewers(ctx context.Context, prID string, logins []string) error
+}
+
+// APIError - ответ API внешней системы с кодом ошибки
+type APIError struct {
+	Status int
+	Body   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("code host responded %d: %s", e.Status, e.Body)
+}
+
+// Temporary сообщает, может ли повтор запроса завершиться успешно.
+func (e *APIError) Temporary() bool {
+	return e.Status == http.StatusTooManyRequests || e.Status >= 500
+}
+
+// maxErrorBody - сколько байт тела ответа с ошибкой попадает в APIError
+const maxErrorBody = 512
+
+// CheckResponse возвращает *APIError, если resp не успешен.
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &APIError{Status: resp.StatusCode, Body: string(body)}
+}
+
+// PRReader - чтение PR (реализуется repository.Repo)
+type PRReader interface {
+	GetPR(ctx context.Context, prID string) (domain.PullRequest, error)
+}
+
+// LoginStore - логины пользователей во внешних системах (реализуется repository.Repo)
+type LoginStore interface {
+	GetExternalLogin(ctx context.Context, provider, userID string) (string, error)
+}
+
+// ReviewerSync передаёт назначения ревьюверов в систему, из которой пришёл PR.
+// Используется как получатель событий outbox: временные ошибки API возвращаются
+// для повтора, остальные только логируются.
+type ReviewerSync struct {
+	// Clients - клиенты по provider; PR из систем без клиента пропускаются
+	Clients  map[string]CodeHostClient
+	PRs      PRReader
+	Accounts LoginStore
+	Log      infra.Logger
+}
+
+func NewReviewerSync(prs PRReader, accounts LoginStore, log infra.Logger, clients map[string]CodeHostClient) *ReviewerSync {
+	return &ReviewerSync{Clients: clients, PRs: prs, Accounts: accounts, Log: log}
+}
+
+func (s *ReviewerSync) Name() string {
+	return "reviewer_sync"
+}
+
+func (s *ReviewerSync) Deliver(ctx context.Context, e domain.Event) error {
+	if e.Type != domain.EventReviewerAssigned && e.Type != domain.EventReviewerReassigned {
+		return nil
+	}
+	var a domain.ReviewerAssignment
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return fmt.Errorf("decode %s event %s: %w", e.Type, e.ID, err)
+	}
+
+	pr, err := s.PRs.GetPR(ctx, a.PullRequestID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	client, ok := s.Clients[pr.Source]
+	if !ok {
+		return nil
+	}
+
+	if a.OldReviewerID != "" {
+		if err := s.push(ctx, pr, a.OldReviewerID, client.RemoveReviewers); err != nil {
+			return err
+		}
+	}
+	return s.push(ctx, pr, a.ReviewerID, client.RequestReviewers)
+}
+
+// push вызывает call для логина пользователя userID во внешней системе PR
+func (s *ReviewerSync) push(ctx context.Context, pr domain.PullRequest, userID string,
+	call func(ctx context.Context, prID string, logins []string) error) error {
+	login, err := s.Accounts.GetExternalLogin(ctx, pr.Source, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.Log.Errorf("ReviewerSync: user %s has no %s login, PR %s not updated", userID, pr.Source, pr.ID)
+			return nil
+		}
+		return err
+	}
+	err = call(ctx, pr.ID, []string{login})
+	var apiErr *APIError
+	if errors.Is(err, ErrInvalidPRID) || (errors.As(err, &apiErr) && !apiErr.Temporary()) {
+		s.Log.Errorf("ReviewerSync: %s PR %s reviewer %s not updated: %v", pr.Source, pr.ID, login, err)
+		return nil
+	}
+	return err
+}
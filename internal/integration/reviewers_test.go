@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// fakeClient записывает вызовы и возвращает err на каждый из них
+type fakeClient struct {
+	calls []string
+	err   error
+}
+
+func (c *fakeClient) RequestReviewers(ctx context.Context, prID string, logins []string) error {
+	c.calls = append(c.calls, "request "+prID+" "+logins[0])
+	return c.err
+}
+
+func (c *fakeClient) RemoveReviewers(ctx context.Context, prID string, logins []string) error {
+	c.calls = append(c.calls, "remove "+prID+" "+logins[0])
+	return c.err
+}
+
+type fakeStore struct {
+	prs    map[string]domain.PullRequest
+	logins map[string]string
+}
+
+func (s fakeStore) GetPR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	if pr, ok := s.prs[prID]; ok {
+		return pr, nil
+	}
+	return domain.PullRequest{}, repository.ErrNotFound
+}
+
+func (s fakeStore) GetExternalLogin(ctx context.Context, provider, userID string) (string, error) {
+	if login, ok := s.logins[provider+"/"+userID]; ok {
+		return login, nil
+	}
+	return "", repository.ErrNotFound
+}
+
+func newStore() fakeStore {
+	return fakeStore{
+		prs: map[string]domain.PullRequest{
+			"acme/api#7": {ID: "acme/api#7", Source: domain.ProviderGitHub},
+			"pr-1":       {ID: "pr-1"},
+		},
+		logins: map[string]string{"github/u2": "bob", "github/u3": "carol"},
+	}
+}
+
+func TestReviewerSync_Deliver(t *testing.T) {
+	// событие из outbox: Data приходит как json.RawMessage
+	reassigned := domain.NewReviewerReassignedEvent(domain.Replacement{PullRequestID: "acme/api#7", OldReviewerID: "u2", NewReviewerID: "u3"})
+	raw, _ := json.Marshal(reassigned.Data)
+	reassigned.Data = json.RawMessage(raw)
+
+	tests := []struct {
+		name  string
+		event domain.Event
+		want  []string
+	}{
+		{"assigned", domain.NewReviewerAssignedEvent("acme/api#7", "u2"),
+			[]string{"request acme/api#7 bob"}},
+		{"reassigned", reassigned,
+			[]string{"remove acme/api#7 bob", "request acme/api#7 carol"}},
+		{"reviewer without login", domain.NewReviewerAssignedEvent("acme/api#7", "u9"), nil},
+		{"pr created in service", domain.NewReviewerAssignedEvent("pr-1", "u2"), nil},
+		{"unknown pr", domain.NewReviewerAssignedEvent("pr-404", "u2"), nil},
+		{"other event", domain.NewEvent(domain.EventPRCreated, domain.PullRequest{ID: "acme/api#7"}), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{}
+			s := NewReviewerSync(newStore(), newStore(), infra.NewStdLogger(), map[string]CodeHostClient{domain.ProviderGitHub: client})
+
+			if err := s.Deliver(context.Background(), tt.event); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(client.calls, tt.want) {
+				t.Fatalf("expected calls %v, got %v", tt.want, client.calls)
+			}
+		})
+	}
+}
+
+func TestReviewerSync_RetriesOnlyTemporaryErrors(t *testing.T) {
+	network := errors.New("connection reset")
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{"server error", &APIError{Status: 502}, &APIError{Status: 502}},
+		{"rate limited", &APIError{Status: 429}, &APIError{Status: 429}},
+		{"network error", network, network},
+		{"not a collaborator", &APIError{Status: 422}, nil},
+		{"invalid pr id", ErrInvalidPRID, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{err: tt.err}
+			s := NewReviewerSync(newStore(), newStore(), infra.NewStdLogger(), map[string]CodeHostClient{domain.ProviderGitHub: client})
+
+			err := s.Deliver(context.Background(), domain.NewReviewerAssignedEvent("acme/api#7", "u2"))
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Fatalf("expected err %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
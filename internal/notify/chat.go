@@ -0,0 +1,152 @@
+// Package notify сообщает людям о назначениях ревьюверов, merge и ожидающих ревью.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+)
+
+// Store - данные для текста уведомлений (реализуется repository.Repo)
+type Store interface {
+	GetPR(ctx context.Context, prID string) (domain.PullRequest, error)
+	GetUserByID(ctx context.Context, userID string) (domain.User, error)
+	GetTeamByID(ctx context.Context, teamID int) (domain.Team, error)
+}
+
+// ChatMessage - тело incoming webhook Slack и Mattermost
+type ChatMessage struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+// Chat пишет в чат команды автора PR через её incoming webhook; реализует
+// usecase.Notifier и вызывается из outbox через usecase.NotifierSink. Команды без
+// вебхука уведомлений не получают. Ошибки доставки в чат только логируются.
+type Chat struct {
+	Store   Store
+	HTTP    *http.Client
+	Log     infra.Logger
+	Timeout time.Duration
+}
+
+func NewChat(store Store, log infra.Logger) *Chat {
+	return &Chat{Store: store, HTTP: &http.Client{}, Log: log, Timeout: 5 * time.Second}
+}
+
+func (c *Chat) ReviewersAssigned(ctx context.Context, prID string, reviewerIDs []string) {
+	ctx, cancel := c.detach(ctx)
+	defer cancel()
+	pr, author, team, ok := c.load(ctx, prID)
+	if !ok {
+		return
+	}
+	c.post(ctx, team, fmt.Sprintf("%s: review requested on %s", c.mentions(ctx, reviewerIDs...), describe(pr, author)))
+}
+
+func (c *Chat) ReviewerReassigned(ctx context.Context, r domain.Replacement) {
+	ctx, cancel := c.detach(ctx)
+	defer cancel()
+	pr, author, team, ok := c.load(ctx, r.PullRequestID)
+	if !ok {
+		return
+	}
+	c.post(ctx, team, fmt.Sprintf("%s: review requested on %s instead of %s",
+		c.mentions(ctx, r.NewReviewerID), describe(pr, author), c.mentions(ctx, r.OldReviewerID)))
+}
+
+func (c *Chat) PRMerged(ctx context.Context, pr domain.PullRequest) {
+	ctx, cancel := c.detach(ctx)
+	defer cancel()
+	author, team, ok := c.authorTeam(ctx, pr)
+	if !ok {
+		return
+	}
+	text := describe(pr, author) + " merged"
+	if pr.MergeForcedBy != "" {
+		text += " by " + pr.MergeForcedBy + " bypassing the merge policy"
+	}
+	c.post(ctx, team, text)
+}
+
+// detach отвязывает уведомление от отмены вызывающего: изменения уже записаны
+func (c *Chat) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), c.Timeout)
+}
+
+// load находит PR, его автора и команду автора; ok=false - уведомлять некуда
+func (c *Chat) load(ctx context.Context, prID string) (domain.PullRequest, domain.User, domain.Team, bool) {
+	pr, err := c.Store.GetPR(ctx, prID)
+	if err != nil {
+		c.Log.Errorf("Chat: failed to get PR %s: %v", prID, err)
+		return pr, domain.User{}, domain.Team{}, false
+	}
+	author, team, ok := c.authorTeam(ctx, pr)
+	return pr, author, team, ok
+}
+
+func (c *Chat) authorTeam(ctx context.Context, pr domain.PullRequest) (domain.User, domain.Team, bool) {
+	author, err := c.Store.GetUserByID(ctx, pr.AuthorID)
+	if err != nil {
+		c.Log.Errorf("Chat: failed to get author of PR %s: %v", pr.ID, err)
+		return author, domain.Team{}, false
+	}
+	team, err := c.Store.GetTeamByID(ctx, author.TeamID)
+	if err != nil {
+		c.Log.Errorf("Chat: failed to get team of PR %s: %v", pr.ID, err)
+		return author, team, false
+	}
+	return author, team, team.ChatWebhookURL != ""
+}
+
+// mentions перечисляет пользователей через запятую; неизвестные пишутся по id
+func (c *Chat) mentions(ctx context.Context, userIDs ...string) string {
+	names := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		u, err := c.Store.GetUserByID(ctx, id)
+		if err != nil {
+			names = append(names, id)
+			continue
+		}
+		names = append(names, u.Mention())
+	}
+	return strings.Join(names, ", ")
+}
+
+// slackEscaper экранирует управляющие символы разметки Slack; Mattermost
+// понимает те же HTML-сущности
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func describe(pr domain.PullRequest, author domain.User) string {
+	return fmt.Sprintf("%s %q by %s", pr.ID, slackEscaper.Replace(pr.Title), author.Mention())
+}
+
+func (c *Chat) post(ctx context.Context, team domain.Team, text string) {
+	body, err := json.Marshal(ChatMessage{Text: text, Channel: team.ChatChannel})
+	if err != nil {
+		c.Log.Errorf("Chat: failed to encode message: %v", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, team.ChatWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		c.Log.Errorf("Chat: team %s: invalid webhook url: %v", team.Name, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		c.Log.Errorf("Chat: team %s: delivery failed: %v", team.Name, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		c.Log.Errorf("Chat: team %s: webhook responded %d", team.Name, resp.StatusCode)
+	}
+}
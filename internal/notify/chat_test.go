@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/repository"
+	"github.com/you/pr-assign-avito/internal/usecase"
+)
+
+var _ usecase.Notifier = (*Chat)(nil)
+
+type fakeStore struct {
+	prs   map[string]domain.PullRequest
+	users map[string]domain.User
+	teams map[int]domain.Team
+}
+
+func (s *fakeStore) GetPR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	if pr, ok := s.prs[prID]; ok {
+		return pr, nil
+	}
+	return domain.PullRequest{}, repository.ErrNotFound
+}
+
+func (s *fakeStore) GetUserByID(ctx context.Context, userID string) (domain.User, error) {
+	if u, ok := s.users[userID]; ok {
+		return u, nil
+	}
+	return domain.User{}, repository.ErrNotFound
+}
+
+func (s *fakeStore) GetTeamByID(ctx context.Context, teamID int) (domain.Team, error) {
+	if t, ok := s.teams[teamID]; ok {
+		return t, nil
+	}
+	return domain.Team{}, repository.ErrNotFound
+}
+
+// chatStub - локальный incoming webhook, записывает полученные сообщения
+type chatStub struct {
+	mu       sync.Mutex
+	messages []ChatMessage
+}
+
+func (c *chatStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var m ChatMessage
+	if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&m) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.messages = append(c.messages, m)
+	c.mu.Unlock()
+	_, _ = w.Write([]byte("ok"))
+}
+
+func newChatFixture(t *testing.T) (*Chat, *chatStub) {
+	t.Helper()
+	stub := &chatStub{}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	store := &fakeStore{
+		prs: map[string]domain.PullRequest{
+			"pr-1": {ID: "pr-1", Title: "Add <search> & filters", AuthorID: "u1"},
+			"pr-2": {ID: "pr-2", Title: "Quiet team", AuthorID: "u4"},
+		},
+		users: map[string]domain.User{
+			"u1": {ID: "u1", Username: "alice", TeamID: 1, ChatHandle: "@alice"},
+			"u2": {ID: "u2", Username: "bob", TeamID: 1, ChatHandle: "<@U024BE7LH>"},
+			"u3": {ID: "u3", Username: "carl", TeamID: 1},
+			"u4": {ID: "u4", Username: "dana", TeamID: 2},
+		},
+		teams: map[int]domain.Team{
+			1: {ID: 1, Name: "backend", ChatWebhookURL: srv.URL + "/hooks/abc", ChatChannel: "backend-reviews"},
+			2: {ID: 2, Name: "mobile"},
+		},
+	}
+	return NewChat(store, infra.NewStdLogger()), stub
+}
+
+func TestChat_Messages(t *testing.T) {
+	ctx := context.Background()
+	chat, stub := newChatFixture(t)
+
+	chat.ReviewersAssigned(ctx, "pr-1", []string{"u2", "u3"})
+	chat.ReviewerReassigned(ctx, domain.Replacement{PullRequestID: "pr-1", OldReviewerID: "u3", NewReviewerID: "u2"})
+	chat.PRMerged(ctx, domain.PullRequest{ID: "pr-1", Title: "Add <search> & filters", AuthorID: "u1", MergeForcedBy: "admin-1"})
+
+	const pr = `pr-1 "Add &lt;search&gt; &amp; filters" by @alice`
+	want := []ChatMessage{
+		{Text: "<@U024BE7LH>, carl: review requested on " + pr, Channel: "backend-reviews"},
+		{Text: "<@U024BE7LH>: review requested on " + pr + " instead of carl", Channel: "backend-reviews"},
+		{Text: pr + " merged by admin-1 bypassing the merge policy", Channel: "backend-reviews"},
+	}
+	if !reflect.DeepEqual(stub.messages, want) {
+		t.Fatalf("expected %+v, got %+v", want, stub.messages)
+	}
+}
+
+func TestChat_DeliversOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	chat, stub := newChatFixture(t)
+	sink := usecase.NewNotifierSink(chat)
+
+	events := []domain.Event{
+		domain.NewEvent(domain.EventPRCreated, domain.PullRequest{ID: "pr-1", AuthorID: "u1"}),
+		domain.NewReviewerAssignedEvent("pr-1", "u3"),
+		domain.NewReviewerReassignedEvent(domain.Replacement{PullRequestID: "pr-1", OldReviewerID: "u3", NewReviewerID: "u2"}),
+		domain.NewEvent(domain.EventPRMerged, domain.PullRequest{ID: "pr-1", Title: "Add <search> & filters", AuthorID: "u1"}),
+	}
+	for _, e := range events {
+		if err := sink.Deliver(ctx, e); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	const pr = `pr-1 "Add &lt;search&gt; &amp; filters" by @alice`
+	want := []ChatMessage{
+		{Text: "carl: review requested on " + pr, Channel: "backend-reviews"},
+		{Text: "<@U024BE7LH>: review requested on " + pr + " instead of carl", Channel: "backend-reviews"},
+		{Text: pr + " merged", Channel: "backend-reviews"},
+	}
+	if !reflect.DeepEqual(stub.messages, want) {
+		t.Fatalf("expected %+v, got %+v", want, stub.messages)
+	}
+}
+
+func TestChat_SkipsTeamsWithoutWebhook(t *testing.T) {
+	ctx := context.Background()
+	chat, stub := newChatFixture(t)
+
+	chat.ReviewersAssigned(ctx, "pr-2", []string{"u1"})
+	chat.ReviewersAssigned(ctx, "pr-404", []string{"u1"})
+
+	if len(stub.messages) != 0 {
+		t.Fatalf("expected no messages, got %+v", stub.messages)
+	}
+}
+
+func TestChat_SendsAfterRequestCanceled(t *testing.T) {
+	chat, stub := newChatFixture(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	chat.ReviewersAssigned(ctx, "pr-1", []string{"u2"})
+
+	if len(stub.messages) != 1 {
+		t.Fatalf("expected message despite canceled request, got %+v", stub.messages)
+	}
+}
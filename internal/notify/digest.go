@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+// PendingReviews отбирает из PR пользователя те, что ждут ревью.
+func PendingReviews(prs []domain.PullRequest) []domain.PullRequest {
+	var pending []domain.PullRequest
+	for _, pr := range prs {
+		if pr.Status.IsOpen() {
+			pending = append(pending, pr)
+		}
+	}
+	return pending
+}
+
+// DigestEmail - письмо со сводкой ожидающих ревью пользователя u.
+func DigestEmail(u domain.User, pending []domain.PullRequest) Email {
+	subject := fmt.Sprintf("%d pull requests waiting for your review", len(pending))
+	if len(pending) == 1 {
+		subject = "1 pull request waiting for your review"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\nPull requests waiting for your review:\n\n", u.Username)
+	for _, pr := range pending {
+		fmt.Fprintf(&b, "- %s %q (%s", pr.ID, pr.Title, pr.Status)
+		if r, ok := pr.ReviewDecisions[u.ID]; ok {
+			fmt.Fprintf(&b, ", your last decision: %s", r.Decision)
+		}
+		fmt.Fprintf(&b, ", open since %s)\n", pr.CreatedAt.Format("2006-01-02"))
+	}
+	b.WriteString("\nTo unsubscribe, set digest_opt_out with POST /users/setDigest.\n")
+	return Email{To: u.Email, Subject: subject, Body: b.String()}
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/notify/smtptest"
+)
+
+func TestSMTP_Send(t *testing.T) {
+	srv, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start smtp server: %v", err)
+	}
+	defer srv.Close()
+	s := NewSMTP(srv.Addr, "Reviews <reviews@example.com>", "bot", "s3cret")
+
+	body := "Привет, bob!\n.dot-leading line and a long line " + strings.Repeat("x", 100) + "\n"
+	if err := s.Send(context.Background(), Email{To: "bob@example.com", Subject: "Ревью ждут", Body: body}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	m := msgs[0]
+	if m.From != "reviews@example.com" || !reflect.DeepEqual(m.To, []string{"bob@example.com"}) || m.Auth != "bot:s3cret" {
+		t.Fatalf("unexpected envelope: %+v", m)
+	}
+	h := m.Header()
+	subject, _ := new(mime.WordDecoder).DecodeHeader(h.Get("Subject"))
+	if subject != "Ревью ждут" || h.Get("To") != "<bob@example.com>" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+	i := strings.Index(m.Data, "\r\n\r\n")
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(m.Data[i+4:])))
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if got := strings.ReplaceAll(string(decoded), "\r\n", "\n"); got != body {
+		t.Fatalf("expected body %q, got %q", body, got)
+	}
+}
+
+func TestSMTP_RejectedRecipient(t *testing.T) {
+	srv, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start smtp server: %v", err)
+	}
+	defer srv.Close()
+	srv.RejectRcpt["gone@example.com"] = true
+	s := NewSMTP(srv.Addr, "reviews@example.com", "", "")
+
+	if err := s.Send(context.Background(), Email{To: "gone@example.com", Subject: "x", Body: "x"}); err == nil {
+		t.Fatalf("expected error for rejected recipient")
+	}
+	if err := s.Send(context.Background(), Email{To: "not an address", Subject: "x", Body: "x"}); err == nil {
+		t.Fatalf("expected error for invalid recipient")
+	}
+	if len(srv.Messages()) != 0 {
+		t.Fatalf("expected no messages, got %+v", srv.Messages())
+	}
+}
+
+func TestDigestEmail(t *testing.T) {
+	created := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	prs := []domain.PullRequest{
+		{ID: "pr-1", Title: "Add search", Status: domain.StatusOpen, CreatedAt: created},
+		{ID: "pr-2", Title: "Old", Status: domain.StatusMerged, CreatedAt: created},
+		{ID: "pr-3", Title: "Fix auth", Status: domain.StatusReopened, CreatedAt: created,
+			ReviewDecisions: map[string]domain.Review{"u2": {Decision: domain.DecisionChangesRequested}}},
+		{ID: "pr-4", Title: "WIP", Status: domain.StatusDraft, CreatedAt: created},
+	}
+	pending := PendingReviews(prs)
+	e := DigestEmail(domain.User{ID: "u2", Username: "bob", Email: "bob@example.com"}, pending)
+
+	if e.To != "bob@example.com" || e.Subject != "2 pull requests waiting for your review" {
+		t.Fatalf("unexpected email: %+v", e)
+	}
+	for _, want := range []string{
+		`- pr-1 "Add search" (OPEN, open since 2026-10-12)`,
+		`- pr-3 "Fix auth" (REOPENED, your last decision: CHANGES_REQUESTED, open since 2026-10-12)`,
+	} {
+		if !strings.Contains(e.Body, want) {
+			t.Fatalf("expected body to contain %q, got:\n%s", want, e.Body)
+		}
+	}
+	if strings.Contains(e.Body, "pr-2") || strings.Contains(e.Body, "pr-4") {
+		t.Fatalf("expected only open PRs, got:\n%s", e.Body)
+	}
+}
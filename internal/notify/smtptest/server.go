@@ -0,0 +1,154 @@
+// Package smtptest - SMTP-сервер в памяти процесса для тестов отправки писем.
+package smtptest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+)
+
+// Message - принятое сервером письмо
+type Message struct {
+	From string
+	To   []string
+	// Auth - "логин:пароль" из AUTH PLAIN, пусто без аутентификации
+	Auth string
+	Data string
+}
+
+// Header разбирает заголовки письма.
+func (m Message) Header() mail.Header {
+	msg, err := mail.ReadMessage(strings.NewReader(m.Data))
+	if err != nil {
+		return mail.Header{}
+	}
+	return msg.Header
+}
+
+// Server принимает письма на 127.0.0.1 и складывает их в память. RejectRcpt
+// заставляет сервер отвечать 550 на RCPT TO для этих адресов.
+type Server struct {
+	Addr       string
+	RejectRcpt map[string]bool
+
+	ln       net.Listener
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{Addr: ln.Addr().String(), RejectRcpt: map[string]bool{}, ln: ln}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Messages возвращает принятые письма.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message{}, s.messages...)
+}
+
+func (s *Server) Close() {
+	_ = s.ln.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.session(conn)
+		}()
+	}
+}
+
+func (s *Server) session(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 smtptest ready")
+	var m Message
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-smtptest")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			raw, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(line[len("AUTH PLAIN"):]))
+			// identity \x00 username \x00 password
+			parts := strings.SplitN(string(raw), "\x00", 3)
+			if len(parts) == 3 {
+				m.Auth = parts[1] + ":" + parts[2]
+			}
+			reply("235 authenticated")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m.From = address(line[len("MAIL FROM:"):])
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := address(line[len("RCPT TO:"):])
+			if s.RejectRcpt[to] {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			m.To = append(m.To, to)
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			m.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, m)
+			s.mu.Unlock()
+			m = Message{Auth: m.Auth}
+			reply("250 queued")
+		case cmd == "RSET":
+			m = Message{Auth: m.Auth}
+			reply("250 ok")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func address(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "<>")
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/you/pr-assign-avito/internal/domain"
 )
@@ -12,30 +13,115 @@ var (
 	ErrTeamExists  = errors.New("team exists")
 	ErrPRMerged    = errors.New("pr merged")
 	ErrNotAssigned = errors.New("not assigned")
+	ErrUnknownTeam = errors.New("unknown team")
+	ErrPRClosed    = errors.New("pr closed")
+
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrHistoryUnavailable - история PR на запрошенный момент не сохранилась
+	ErrHistoryUnavailable = errors.New("history unavailable")
 )
 
 type Repo interface {
-	CreateTeamWithMembers(ctx context.Context, teamName string, members []domain.User) error
+	CreateTeamWithMembers(ctx context.Context, team domain.Team, members []domain.TeamMember) error
 	GetTeamByName(ctx context.Context, name string) (domain.Team, []domain.User, error)
+	GetTeamByID(ctx context.Context, teamID int) (domain.Team, error)
+	UpdateTeam(ctx context.Context, team domain.Team) error
+	SetTeamCodeowners(ctx context.Context, teamName, doc string) error
 	SetUserActive(ctx context.Context, userID string, active bool) (domain.User, error)
+	SetUserMaxReviews(ctx context.Context, userID string, maxReviews int) (domain.User, error)
+	SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error)
+	SetUserChatHandle(ctx context.Context, userID, handle string) (domain.User, error)
+	SetUserDigest(ctx context.Context, userID, email string, optOut bool) (domain.User, error)
+	// DeactivateUsers в одной транзакции снимает флаг активности с пользователей
+	// и выполняет замены ревьюверов на открытых PR.
+	DeactivateUsers(ctx context.Context, userIDs []string, replacements []domain.Replacement) error
 	GetUserByID(ctx context.Context, userID string) (domain.User, error)
 	GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error)
 
+	AddAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error)
+	GetUserAbsences(ctx context.Context, userID string) ([]domain.Absence, error)
+	UpdateAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error)
+	DeleteAbsence(ctx context.Context, absenceID int) error
+	// DueAbsenceReassignments возвращает идущие на момент now отсутствия с флагом
+	// reassign_open_reviews, по которым переназначение ещё не выполнено.
+	DueAbsenceReassignments(ctx context.Context, now time.Time) ([]domain.Absence, error)
+	MarkAbsenceReassigned(ctx context.Context, absenceID int, at time.Time) error
+
 	CreatePR(ctx context.Context, pr domain.PullRequest, status string) error
 	GetPR(ctx context.Context, prID string) (domain.PullRequest, error)
+	// GetPRAt возвращает статус и ревьюверов PR на момент at по его потоку событий;
+	// ErrNotFound, если PR к этому моменту ещё не был создан, и ErrHistoryUnavailable,
+	// если at раньше, чем поток PR начал вестись полностью.
+	GetPRAt(ctx context.Context, prID string, at time.Time) (domain.PullRequest, error)
+	// GetActiveTeamMembersExcluding возвращает доступных кандидатов в ревьюверы
+	// с текущей нагрузкой в User.OpenReviews.
 	GetActiveTeamMembersExcluding(ctx context.Context, teamID int, exclude []string) ([]domain.User, error)
 	GetPRReviewers(ctx context.Context, prID string) ([]string, error)
-	ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string) error
-	MergePR(ctx context.Context, prID string) error
+	// ReplacePRReviewer заменяет ревьювера и записывает замену с причиной reason в журнал назначений.
+	ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string, reason domain.AssignmentReason) error
+	// AddReview записывает решение ревьювера, если он назначен на PR и ревьюверов PR ещё можно менять.
+	AddReview(ctx context.Context, r domain.Review) (domain.Review, error)
+	// TransitionPR атомарно переводит PR в статус to, проверяя допустимость перехода.
+	// Перевод в текущий статус ничего не меняет.
+	TransitionPR(ctx context.Context, prID string, to domain.PRStatus) error
+	// MergePR атомарно переводит PR в MERGED, если выполнена политика merge команды автора;
+	// иначе возвращает невыполненные условия. Непустой forcedBy отключает проверку и сохраняется в PR.
+	MergePR(ctx context.Context, prID, forcedBy string) ([]string, error)
+	// GetPRHistory возвращает журнал назначений PR в порядке записи.
+	GetPRHistory(ctx context.Context, prID string) ([]domain.AssignmentEvent, error)
 	PRExists(ctx context.Context, prID string) (bool, error)
 	IsReviewerAssigned(ctx context.Context, prID, userID string) (bool, error)
 	GetPRAuthor(ctx context.Context, prID string) (string, error)
 	HasOpenPRsAsReviewer(ctx context.Context, userID string) (bool, error)
 	GetReviewerStats(ctx context.Context) ([]ReviewerStat, error)
+	// GetOverduePRs возвращает открытые PR, ревьюверы которых не ответили
+	// к моменту now в срок SLA команды автора; самые просроченные идут первыми.
+	GetOverduePRs(ctx context.Context, now time.Time) ([]domain.OverduePR, error)
+	// GetStaleReviews возвращает назначения на открытых PR, по которым ревьювер не ответил
+	// дольше порога эскалации команды автора и которые ещё не эскалировались.
+	GetStaleReviews(ctx context.Context, now time.Time) ([]domain.StaleReview, error)
+	// AddEscalationReviewer добавляет на PR ещё одного ревьювера и помечает зависшее
+	// назначение staleReviewerID как эскалированное.
+	AddEscalationReviewer(ctx context.Context, prID, staleReviewerID, newReviewerID string) error
+
+	AddWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int) error
+
+	// LinkExternalAccount связывает логин внешней системы с пользователем, заменяя прежнюю связь.
+	LinkExternalAccount(ctx context.Context, a domain.ExternalAccount) (domain.ExternalAccount, error)
+	UnlinkExternalAccount(ctx context.Context, provider, login string) error
+	GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error)
+	// GetExternalLogin возвращает логин пользователя во внешней системе.
+	GetExternalLogin(ctx context.Context, provider, userID string) (string, error)
+}
+
+// Locker даёт выполнить работу только одной из нескольких реплик сервиса.
+type Locker interface {
+	// WithAdvisoryLock выполняет fn под блокировкой key; false - блокировку держит другая реплика.
+	WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error)
+}
+
+// Outbox - события, записанные в одной транзакции с породившими их изменениями
+// и ожидающие доставки.
+type Outbox interface {
+	// PendingEvents возвращает до limit недоставленных событий, следующая попытка
+	// доставки которых наступила к now, в порядке записи.
+	PendingEvents(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error)
+	MarkEventDelivered(ctx context.Context, seq int64) error
+	// RetryEventLater откладывает следующую попытку доставки до at; deliveredTo -
+	// получатели, которые событие уже приняли и повторно его не получат.
+	RetryEventLater(ctx context.Context, seq int64, reason string, at time.Time, deliveredTo []string) error
+	// MarkEventDead прекращает попытки доставить событие.
+	MarkEventDead(ctx context.Context, seq int64, reason string) error
 }
 
 type ReviewerStat struct {
 	UserID   string
 	Username string
 	Count    int
+	// ReviewedCount - назначения, по которым ревьювер уже записал решение;
+	// AvgTimeToFirstReview считается только по ним
+	ReviewedCount        int
+	AvgTimeToFirstReview time.Duration
 }
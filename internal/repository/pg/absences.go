@@ -0,0 +1,102 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+const absenceColumns = "id, user_id, starts_at, ends_at, reason, reassign_open_reviews, reassigned_at"
+
+func scanAbsence(row pgx.Row) (domain.Absence, error) {
+	var a domain.Absence
+	err := row.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason, &a.ReassignOpenReviews, &a.ReassignedAt)
+	return a, err
+}
+
+func (p *PGRepo) AddAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	err := p.pool.QueryRow(ctx, `
+        INSERT INTO user_absences (user_id, starts_at, ends_at, reason, reassign_open_reviews)
+        VALUES ($1,$2,$3,$4,$5)
+        RETURNING id
+    `, a.UserID, a.StartsAt, a.EndsAt, a.Reason, a.ReassignOpenReviews).Scan(&a.ID)
+	if err != nil {
+		return domain.Absence{}, err
+	}
+	return a, nil
+}
+
+func (p *PGRepo) GetUserAbsences(ctx context.Context, userID string) ([]domain.Absence, error) {
+	return p.queryAbsences(ctx, `
+        SELECT `+absenceColumns+`
+        FROM user_absences
+        WHERE user_id=$1
+        ORDER BY starts_at
+    `, userID)
+}
+
+// UpdateAbsence при переносе начала отсутствия сбрасывает отметку о переназначении,
+// чтобы ревью переназначились заново, когда начнётся новый период.
+func (p *PGRepo) UpdateAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	a, err := scanAbsence(p.pool.QueryRow(ctx, `
+        UPDATE user_absences
+        SET starts_at=$2, ends_at=$3, reason=$4, reassign_open_reviews=$5,
+            reassigned_at = CASE WHEN starts_at=$2 THEN reassigned_at END
+        WHERE id=$1
+        RETURNING `+absenceColumns,
+		a.ID, a.StartsAt, a.EndsAt, a.Reason, a.ReassignOpenReviews))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Absence{}, repository.ErrNotFound
+		}
+		return domain.Absence{}, err
+	}
+	return a, nil
+}
+
+func (p *PGRepo) DeleteAbsence(ctx context.Context, absenceID int) error {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM user_absences WHERE id=$1", absenceID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (p *PGRepo) DueAbsenceReassignments(ctx context.Context, now time.Time) ([]domain.Absence, error) {
+	return p.queryAbsences(ctx, `
+        SELECT `+absenceColumns+`
+        FROM user_absences
+        WHERE reassign_open_reviews AND reassigned_at IS NULL
+          AND starts_at <= $1 AND ends_at > $1
+        ORDER BY starts_at, id
+    `, now)
+}
+
+func (p *PGRepo) MarkAbsenceReassigned(ctx context.Context, absenceID int, at time.Time) error {
+	_, err := p.pool.Exec(ctx, "UPDATE user_absences SET reassigned_at=$2 WHERE id=$1", absenceID, at)
+	return err
+}
+
+func (p *PGRepo) queryAbsences(ctx context.Context, query string, args ...interface{}) ([]domain.Absence, error) {
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.Absence
+	for rows.Next() {
+		a, err := scanAbsence(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
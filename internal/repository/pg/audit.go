@@ -0,0 +1,57 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// insertAssignmentEvents пишет в журнал назначений изменения, сделанные в транзакции tx;
+// инициатор берётся из ctx
+func insertAssignmentEvents(ctx context.Context, tx pgx.Tx, events ...domain.AssignmentEvent) error {
+	actor := domain.ActorFrom(ctx)
+	for _, e := range events {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO assignment_events (pr_id, actor, old_reviewer_id, new_reviewer_id, reason)
+            VALUES ($1,$2,NULLIF($3,''),NULLIF($4,''),$5)
+        `, e.PullRequestID, actor, e.OldReviewerID, e.NewReviewerID, string(e.Reason))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PGRepo) GetPRHistory(ctx context.Context, prID string) ([]domain.AssignmentEvent, error) {
+	exists, err := p.PRExists(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, repository.ErrNotFound
+	}
+	rows, err := p.pool.Query(ctx, `
+        SELECT id, pr_id, actor, COALESCE(old_reviewer_id, ''), COALESCE(new_reviewer_id, ''), reason, created_at
+        FROM assignment_events
+        WHERE pr_id=$1
+        ORDER BY id
+    `, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := []domain.AssignmentEvent{}
+	for rows.Next() {
+		var e domain.AssignmentEvent
+		var reason string
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Actor, &e.OldReviewerID, &e.NewReviewerID, &reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Reason = domain.AssignmentReason(reason)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,39 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+// DigestRecipients возвращает активных пользователей с адресом, не отказавшихся от
+// дайджеста и ещё не получивших дайджест за день day. Пользователи, отправка которым
+// не удалась позже retryBefore, пропускаются до следующей попытки.
+func (p *PGRepo) DigestRecipients(ctx context.Context, day, retryBefore time.Time) ([]domain.User, error) {
+	return p.queryUsers(ctx, "SELECT "+userColumns+` FROM users u JOIN teams t ON t.id = u.team_id
+        WHERE u.is_active AND u.email <> '' AND NOT u.digest_opt_out
+            AND (u.digest_sent_on IS NULL OR u.digest_sent_on < $1::date)
+            AND (u.digest_failed_at IS NULL OR u.digest_failed_at <= $2)
+        ORDER BY u.id`, day.Format(time.DateOnly), retryBefore)
+}
+
+// MarkDigestSent отмечает, что дайджест за день day пользователю отправлен.
+func (p *PGRepo) MarkDigestSent(ctx context.Context, userID string, day time.Time) error {
+	_, err := p.pool.Exec(ctx, "UPDATE users SET digest_sent_on=$2::date WHERE id=$1", userID, day.Format(time.DateOnly))
+	return err
+}
+
+// MarkDigestFailed записывает неудачную отправку дайджеста за день day в момент at и
+// возвращает число неудач за этот день.
+func (p *PGRepo) MarkDigestFailed(ctx context.Context, userID string, day, at time.Time) (int, error) {
+	var failures int
+	err := p.pool.QueryRow(ctx, `
+        UPDATE users
+        SET digest_failures = CASE WHEN digest_failed_at >= $2 THEN digest_failures + 1 ELSE 1 END,
+            digest_failed_at = $3
+        WHERE id=$1
+        RETURNING digest_failures
+    `, userID, day, at).Scan(&failures)
+	return failures, err
+}
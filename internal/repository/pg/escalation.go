@@ -0,0 +1,103 @@
+package pg
+
+import (
+	"context"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+func (p *PGRepo) GetStaleReviews(ctx context.Context, now time.Time) ([]domain.StaleReview, error) {
+	rows, err := p.pool.Query(ctx, `
+        SELECT rv.pr_id, rv.reviewer_id, rv.assigned_at, t.escalation_action
+        FROM pr_reviewers rv
+        JOIN pull_requests pr ON pr.id = rv.pr_id
+        JOIN pr_statuses st ON pr.status_id = st.id
+        JOIN users a ON a.id = pr.author_id
+        JOIN teams t ON t.id = a.team_id
+        WHERE st.name IN ('OPEN', 'REOPENED')
+          AND t.escalation_hours > 0
+          AND rv.escalated_at IS NULL
+          AND rv.assigned_at + make_interval(hours => t.escalation_hours) < $1
+          AND NOT EXISTS (
+              SELECT 1 FROM pr_reviews r
+              WHERE r.pr_id = rv.pr_id AND r.reviewer_id = rv.reviewer_id AND r.created_at >= rv.assigned_at
+          )
+        ORDER BY rv.assigned_at, rv.pr_id, rv.reviewer_id
+    `, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.StaleReview
+	for rows.Next() {
+		var s domain.StaleReview
+		var action string
+		if err := rows.Scan(&s.PullRequestID, &s.ReviewerID, &s.AssignedAt, &action); err != nil {
+			return nil, err
+		}
+		s.Action = domain.EscalationAction(action)
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+func (p *PGRepo) AddEscalationReviewer(ctx context.Context, prID, staleReviewerID, newReviewerID string) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	state, err := lockPRState(ctx, tx, prID)
+	if err != nil {
+		return err
+	}
+	if err := reviewersLockedErr(string(state.PR.Status)); err != nil {
+		return err
+	}
+	if !state.IsAssigned(staleReviewerID) {
+		return repository.ErrNotAssigned
+	}
+	err = savePREvents(ctx, tx, &state,
+		domain.NewPRReviewerEscalated(prID, staleReviewerID),
+		domain.NewPRReviewerAssigned(prID, newReviewerID))
+	if err != nil {
+		return err
+	}
+	if err := insertEvents(ctx, tx, domain.NewReviewerAssignedEvent(prID, newReviewerID)); err != nil {
+		return err
+	}
+	a := domain.AssignmentEvent{PullRequestID: prID, NewReviewerID: newReviewerID, Reason: domain.ReasonEscalationAdd}
+	if err := insertAssignmentEvents(ctx, tx, a); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// WithAdvisoryLock выполняет fn, только если удалось взять сессионную advisory-блокировку key.
+// Блокировка держится на отдельном соединении пула и снимается после fn, поэтому из
+// нескольких реплик fn одновременно выполняет только одна.
+func (p *PGRepo) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		// контекст уже может быть отменён, а блокировку нужно снять в любом случае
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+	}()
+	return true, fn(ctx)
+}
@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+func (p *PGRepo) LinkExternalAccount(ctx context.Context, a domain.ExternalAccount) (domain.ExternalAccount, error) {
+	// логины внешних систем не зависят от регистра, поэтому хранятся в нижнем регистре
+	a.Login = strings.ToLower(a.Login)
+	_, err := p.pool.Exec(ctx, `
+        INSERT INTO external_accounts (provider, login, user_id) VALUES ($1,$2,$3)
+        ON CONFLICT (provider, login) DO UPDATE SET user_id = EXCLUDED.user_id
+    `, a.Provider, a.Login, a.UserID)
+	if err != nil {
+		return domain.ExternalAccount{}, err
+	}
+	return a, nil
+}
+
+func (p *PGRepo) UnlinkExternalAccount(ctx context.Context, provider, login string) error {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM external_accounts WHERE provider=$1 AND login=$2",
+		provider, strings.ToLower(login))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (p *PGRepo) GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error) {
+	var userID string
+	err := p.pool.QueryRow(ctx, "SELECT user_id FROM external_accounts WHERE provider=$1 AND login=$2",
+		provider, strings.ToLower(login)).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", repository.ErrNotFound
+		}
+		return "", err
+	}
+	return userID, nil
+}
+
+func (p *PGRepo) GetExternalLogin(ctx context.Context, provider, userID string) (string, error) {
+	var login string
+	err := p.pool.QueryRow(ctx, "SELECT login FROM external_accounts WHERE provider=$1 AND user_id=$2 ORDER BY login LIMIT 1",
+		provider, userID).Scan(&login)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", repository.ErrNotFound
+		}
+		return "", err
+	}
+	return login, nil
+}
@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+// insertEvents записывает события в outbox в транзакции изменения, которое их породило
+func insertEvents(ctx context.Context, tx pgx.Tx, events ...domain.Event) error {
+	for _, e := range events {
+		payload, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+            INSERT INTO outbox (event_id, event_type, payload, occurred_at)
+            VALUES ($1,$2,$3::jsonb,$4)
+        `, e.ID, e.Type, string(payload), e.OccurredAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PGRepo) PendingEvents(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	rows, err := p.pool.Query(ctx, `
+        SELECT id, event_id, event_type, payload, occurred_at, attempts, delivered_to
+        FROM outbox
+        WHERE delivered_at IS NULL AND dead_at IS NULL AND next_attempt_at <= $1
+        ORDER BY id
+        LIMIT $2
+    `, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.OutboxEvent
+	for rows.Next() {
+		var oe domain.OutboxEvent
+		var payload []byte
+		if err := rows.Scan(&oe.Seq, &oe.Event.ID, &oe.Event.Type, &payload, &oe.Event.OccurredAt, &oe.Attempts, &oe.DeliveredTo); err != nil {
+			return nil, err
+		}
+		oe.Event.Data = json.RawMessage(payload)
+		res = append(res, oe)
+	}
+	return res, rows.Err()
+}
+
+func (p *PGRepo) MarkEventDelivered(ctx context.Context, seq int64) error {
+	_, err := p.pool.Exec(ctx, "UPDATE outbox SET delivered_at=now(), attempts=attempts+1, last_error=NULL WHERE id=$1", seq)
+	return err
+}
+
+func (p *PGRepo) RetryEventLater(ctx context.Context, seq int64, reason string, at time.Time, deliveredTo []string) error {
+	if deliveredTo == nil {
+		deliveredTo = []string{}
+	}
+	_, err := p.pool.Exec(ctx, "UPDATE outbox SET attempts=attempts+1, last_error=$2, next_attempt_at=$3, delivered_to=$4 WHERE id=$1",
+		seq, reason, at, deliveredTo)
+	return err
+}
+
+func (p *PGRepo) MarkEventDead(ctx context.Context, seq int64, reason string) error {
+	_, err := p.pool.Exec(ctx, "UPDATE outbox SET dead_at=now(), attempts=attempts+1, last_error=$2 WHERE id=$1", seq, reason)
+	return err
+}
@@ -23,7 +23,7 @@ func NewPGRepo(pool *pgxpool.Pool) *PGRepo {
 	return &PGRepo{pool: pool}
 }
 
-func (p *PGRepo) CreateTeamWithMembers(ctx context.Context, teamName string, members []domain.User) error {
+func (p *PGRepo) CreateTeamWithMembers(ctx context.Context, team domain.Team, members []domain.TeamMember) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -33,7 +33,7 @@ func (p *PGRepo) CreateTeamWithMembers(ctx context.Context, teamName string, mem
 	}()
 
 	var exists bool
-	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)", teamName).Scan(&exists); err != nil {
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM teams WHERE name=$1)", team.Name).Scan(&exists); err != nil {
 		return err
 	}
 	if exists {
@@ -41,14 +41,31 @@ func (p *PGRepo) CreateTeamWithMembers(ctx context.Context, teamName string, mem
 	}
 
 	var teamID int
-	err = tx.QueryRow(ctx, "INSERT INTO teams(name) VALUES ($1) RETURNING id", teamName).Scan(&teamID)
+	minReviewers, maxReviewers := team.ReviewerLimits()
+	err = tx.QueryRow(ctx, `
+        INSERT INTO teams(name, reviewer_strategy, min_reviewers, max_reviewers, required_approvals, allow_changes_requested,
+            review_sla_hours, escalation_hours, escalation_action, chat_webhook_url, chat_channel)
+        VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'reassign'), $10, $11) RETURNING id
+    `, team.Name, team.ReviewerStrategy, minReviewers, maxReviewers, team.RequiredApprovals, team.AllowChangesRequested,
+		team.ReviewSLAHours, team.EscalationHours, string(team.EscalationAction), team.ChatWebhookURL, team.ChatChannel).Scan(&teamID)
 	if err != nil {
 		return err
 	}
+	if err := replaceFallbackTeams(ctx, tx, teamID, team.FallbackTeams); err != nil {
+		return err
+	}
 
+	// непереданные (NULL) настройки участника у существующего пользователя сохраняются
 	for _, m := range members {
-		_, err = tx.Exec(ctx, `INSERT INTO users (id, username, team_id, is_active) VALUES ($1,$2,$3,$4)
-            ON CONFLICT (id) DO UPDATE SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active`, m.ID, m.Username, teamID, m.IsActive)
+		_, err = tx.Exec(ctx, `INSERT INTO users (id, username, team_id, is_active, max_concurrent_reviews, tags, chat_handle,
+                email, digest_opt_out)
+            VALUES ($1,$2,$3,$4,COALESCE($5::int, 0),COALESCE($6::text[], '{}'),COALESCE($7::text, ''),
+                COALESCE($8::text, ''),COALESCE($9::boolean, FALSE))
+            ON CONFLICT (id) DO UPDATE SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active,
+            max_concurrent_reviews=COALESCE($5::int, users.max_concurrent_reviews), tags=COALESCE($6::text[], users.tags),
+            chat_handle=COALESCE($7::text, users.chat_handle), email=COALESCE($8::text, users.email),
+            digest_opt_out=COALESCE($9::boolean, users.digest_opt_out)`,
+			m.ID, m.Username, teamID, m.IsActive, m.MaxConcurrentReviews, m.Tags, m.ChatHandle, m.Email, m.DigestOptOut)
 		if err != nil {
 			return err
 		}
@@ -57,25 +74,106 @@ func (p *PGRepo) CreateTeamWithMembers(ctx context.Context, teamName string, mem
 }
 
 func (p *PGRepo) GetTeamByName(ctx context.Context, name string) (domain.Team, []domain.User, error) {
-	var team domain.Team
-	err := p.pool.QueryRow(ctx, "SELECT id, name FROM teams WHERE name=$1", name).Scan(&team.ID, &team.Name)
+	team, err := scanTeam(p.pool.QueryRow(ctx, "SELECT "+teamColumns+" FROM teams WHERE name=$1", name))
 	if err != nil {
 		return team, nil, repository.ErrNotFound
 	}
-	rows, err := p.pool.Query(ctx, "SELECT id, username, team_id, is_active FROM users WHERE team_id=$1", team.ID)
+	users, err := p.queryUsers(ctx, "SELECT "+userColumns+" FROM users u JOIN teams t ON t.id = u.team_id WHERE u.team_id=$1", team.ID)
 	if err != nil {
 		return team, nil, err
 	}
-	defer rows.Close()
-	var users []domain.User
-	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.TeamID, &u.IsActive); err != nil {
-			return team, nil, err
+	return team, users, nil
+}
+
+func (p *PGRepo) GetTeamByID(ctx context.Context, teamID int) (domain.Team, error) {
+	team, err := scanTeam(p.pool.QueryRow(ctx, "SELECT "+teamColumns+" FROM teams WHERE id=$1", teamID))
+	if err != nil {
+		return domain.Team{}, repository.ErrNotFound
+	}
+	return team, nil
+}
+
+func (p *PGRepo) UpdateTeam(ctx context.Context, team domain.Team) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var teamID int
+	minReviewers, maxReviewers := team.ReviewerLimits()
+	err = tx.QueryRow(ctx, `
+        UPDATE teams
+        SET reviewer_strategy=NULLIF($2, ''), min_reviewers=$3, max_reviewers=$4,
+            required_approvals=$5, allow_changes_requested=$6, review_sla_hours=$7,
+            escalation_hours=$8, escalation_action=COALESCE(NULLIF($9, ''), 'reassign'),
+            chat_webhook_url=$10, chat_channel=$11
+        WHERE name=$1
+        RETURNING id
+    `, team.Name, team.ReviewerStrategy, minReviewers, maxReviewers, team.RequiredApprovals, team.AllowChangesRequested,
+		team.ReviewSLAHours, team.EscalationHours, string(team.EscalationAction), team.ChatWebhookURL, team.ChatChannel).Scan(&teamID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return repository.ErrNotFound
 		}
-		users = append(users, u)
+		return err
 	}
-	return team, users, nil
+	if err := replaceFallbackTeams(ctx, tx, teamID, team.FallbackTeams); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (p *PGRepo) SetTeamCodeowners(ctx context.Context, teamName, doc string) error {
+	tag, err := p.pool.Exec(ctx, "UPDATE teams SET codeowners=NULLIF($2, '') WHERE name=$1", teamName, doc)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// replaceFallbackTeams перезаписывает резервные команды в порядке приоритета
+func replaceFallbackTeams(ctx context.Context, tx pgx.Tx, teamID int, names []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM team_fallbacks WHERE team_id=$1", teamID); err != nil {
+		return err
+	}
+	for i, name := range names {
+		var fallbackID int
+		if err := tx.QueryRow(ctx, "SELECT id FROM teams WHERE name=$1", name).Scan(&fallbackID); err != nil {
+			if err == pgx.ErrNoRows {
+				return repository.ErrUnknownTeam
+			}
+			return err
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO team_fallbacks (team_id, fallback_team_id, position) VALUES ($1,$2,$3)",
+			teamID, fallbackID, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const teamColumns = `id, name, COALESCE(reviewer_strategy, ''), min_reviewers, max_reviewers,
+        ARRAY(SELECT f.fallback_team_id FROM team_fallbacks f WHERE f.team_id = teams.id ORDER BY f.position),
+        ARRAY(SELECT ft.name FROM team_fallbacks f JOIN teams ft ON ft.id = f.fallback_team_id
+              WHERE f.team_id = teams.id ORDER BY f.position),
+        COALESCE(codeowners, ''), required_approvals, allow_changes_requested, review_sla_hours,
+        escalation_hours, escalation_action, chat_webhook_url, chat_channel`
+
+func scanTeam(row pgx.Row) (domain.Team, error) {
+	var t domain.Team
+	var action string
+	err := row.Scan(&t.ID, &t.Name, &t.ReviewerStrategy, &t.MinReviewers, &t.MaxReviewers,
+		&t.FallbackTeamIDs, &t.FallbackTeams, &t.Codeowners, &t.RequiredApprovals, &t.AllowChangesRequested,
+		&t.ReviewSLAHours, &t.EscalationHours, &action, &t.ChatWebhookURL, &t.ChatChannel)
+	t.EscalationAction = domain.EscalationAction(action)
+	return t, err
 }
 
 func (p *PGRepo) SetUserActive(ctx context.Context, userID string, active bool) (domain.User, error) {
@@ -86,33 +184,142 @@ func (p *PGRepo) SetUserActive(ctx context.Context, userID string, active bool)
 	if tag.RowsAffected() == 0 {
 		return domain.User{}, repository.ErrNotFound
 	}
-	var u domain.User
-	err = p.pool.QueryRow(ctx, `
-        SELECT u.id, u.username, u.team_id, t.name, u.is_active
-        FROM users u
-        JOIN teams t ON t.id = u.team_id
-        WHERE u.id=$1
-    `, userID).Scan(&u.ID, &u.Username, &u.TeamID, &u.TeamName, &u.IsActive)
+	return p.GetUserByID(ctx, userID)
+}
+
+func (p *PGRepo) DeactivateUsers(ctx context.Context, userIDs []string, replacements []domain.Replacement) error {
+	tx, err := p.pool.Begin(ctx)
 	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	tag, err := tx.Exec(ctx, "UPDATE users SET is_active=FALSE WHERE id = ANY($1)", userIDs)
+	if err != nil {
+		return err
+	}
+	if int(tag.RowsAffected()) != len(userIDs) {
+		return repository.ErrNotFound
+	}
+
+	for _, r := range replacements {
+		state, err := lockPRState(ctx, tx, r.PullRequestID)
+		if err != nil {
+			return err
+		}
+		if err := reviewersLockedErr(string(state.PR.Status)); err != nil {
+			return err
+		}
+		if !state.IsAssigned(r.OldReviewerID) {
+			return repository.ErrNotAssigned
+		}
+		if err := savePREvents(ctx, tx, &state, domain.NewPRReviewerReplaced(r)); err != nil {
+			return err
+		}
+		if err := insertEvents(ctx, tx, domain.NewReviewerReassignedEvent(r)); err != nil {
+			return err
+		}
+		if err := insertAssignmentEvents(ctx, tx, domain.AssignmentEvent{
+			PullRequestID: r.PullRequestID,
+			OldReviewerID: r.OldReviewerID,
+			NewReviewerID: r.NewReviewerID,
+			Reason:        domain.ReasonTeamDeactivation,
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (p *PGRepo) SetUserMaxReviews(ctx context.Context, userID string, maxReviews int) (domain.User, error) {
+	tag, err := p.pool.Exec(ctx, "UPDATE users SET max_concurrent_reviews=$1 WHERE id=$2", maxReviews, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if tag.RowsAffected() == 0 {
 		return domain.User{}, repository.ErrNotFound
 	}
-	return u, nil
+	return p.GetUserByID(ctx, userID)
+}
+
+func (p *PGRepo) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	tag, err := p.pool.Exec(ctx, "UPDATE users SET tags=COALESCE($1::text[], '{}') WHERE id=$2", tags, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.User{}, repository.ErrNotFound
+	}
+	return p.GetUserByID(ctx, userID)
+}
+
+func (p *PGRepo) SetUserChatHandle(ctx context.Context, userID, handle string) (domain.User, error) {
+	tag, err := p.pool.Exec(ctx, "UPDATE users SET chat_handle=$1 WHERE id=$2", handle, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.User{}, repository.ErrNotFound
+	}
+	return p.GetUserByID(ctx, userID)
+}
+
+func (p *PGRepo) SetUserDigest(ctx context.Context, userID, email string, optOut bool) (domain.User, error) {
+	tag, err := p.pool.Exec(ctx, "UPDATE users SET email=$1, digest_opt_out=$2 WHERE id=$3", email, optOut, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.User{}, repository.ErrNotFound
+	}
+	return p.GetUserByID(ctx, userID)
 }
 
 func (p *PGRepo) GetUserByID(ctx context.Context, userID string) (domain.User, error) {
-	var u domain.User
-	err := p.pool.QueryRow(ctx, `
-        SELECT u.id, u.username, u.team_id, t.name, u.is_active
-        FROM users u
-        JOIN teams t ON t.id = u.team_id
-        WHERE u.id=$1
-    `, userID).Scan(&u.ID, &u.Username, &u.TeamID, &u.TeamName, &u.IsActive)
+	u, err := scanUser(p.pool.QueryRow(ctx, "SELECT "+userColumns+" FROM users u JOIN teams t ON t.id = u.team_id WHERE u.id=$1", userID))
 	if err != nil {
 		return domain.User{}, repository.ErrNotFound
 	}
 	return u, nil
 }
 
+// openReviewsSubquery считает открытые PR, где пользователь u назначен ревьювером
+const openReviewsSubquery = `(
+        SELECT COUNT(*)
+        FROM pr_reviewers rv
+        JOIN pull_requests opr ON opr.id = rv.pr_id
+        JOIN pr_statuses ost ON ost.id = opr.status_id
+        WHERE rv.reviewer_id = u.id AND ost.name IN ('OPEN', 'REOPENED')
+    )`
+
+const userColumns = "u.id, u.username, u.team_id, t.name, u.is_active, u.max_concurrent_reviews, u.tags, u.chat_handle, u.email, u.digest_opt_out, " + openReviewsSubquery
+
+func scanUser(row pgx.Row) (domain.User, error) {
+	var u domain.User
+	err := row.Scan(&u.ID, &u.Username, &u.TeamID, &u.TeamName, &u.IsActive, &u.MaxConcurrentReviews, &u.Tags, &u.ChatHandle, &u.Email, &u.DigestOptOut,
+		&u.OpenReviews)
+	return u, err
+}
+
+func (p *PGRepo) queryUsers(ctx context.Context, q string, args ...interface{}) ([]domain.User, error) {
+	rows, err := p.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []domain.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
 func (p *PGRepo) PRExists(ctx context.Context, prID string) (bool, error) {
 	var exists bool
 	err := p.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pull_requests WHERE id=$1)", prID).Scan(&exists)
@@ -137,58 +344,79 @@ func (p *PGRepo) CreatePR(ctx context.Context, pr domain.PullRequest, status str
 		return err
 	}
 
-	_, err = tx.Exec(ctx, "INSERT INTO pull_requests (id, title, author_id, status_id) VALUES ($1,$2,$3,$4)",
-		pr.ID, pr.Title, pr.AuthorID, statusID)
-	if err != nil {
+	stream := []domain.PREvent{domain.NewPRCreated(pr, domain.PRStatus(status))}
+	events := []domain.Event{domain.NewEvent(domain.EventPRCreated, pr)}
+	var assignments []domain.AssignmentEvent
+	for _, r := range pr.Reviewers {
+		stream = append(stream, domain.NewPRReviewerAssigned(pr.ID, r))
+		events = append(events, domain.NewReviewerAssignedEvent(pr.ID, r))
+		assignments = append(assignments, domain.AssignmentEvent{PullRequestID: pr.ID, NewReviewerID: r, Reason: domain.ReasonPRCreated})
+	}
+	var state domain.PRState
+	if err := savePREvents(ctx, tx, &state, stream...); err != nil {
 		return err
 	}
-
-	for _, r := range pr.Reviewers {
-		_, err = tx.Exec(ctx, "INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1,$2)", pr.ID, r)
-		if err != nil {
-			return err
-		}
-		// Деактивируем ревьювера при назначении
-		_, err = tx.Exec(ctx, "UPDATE users SET is_active=FALSE WHERE id=$1", r)
-		if err != nil {
-			return err
-		}
+	if err := insertEvents(ctx, tx, events...); err != nil {
+		return err
+	}
+	if err := insertAssignmentEvents(ctx, tx, assignments...); err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
 func (p *PGRepo) GetPR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return getPR(ctx, p.pool, prID)
+}
+
+func getPR(ctx context.Context, q querier, prID string) (domain.PullRequest, error) {
 	var pr domain.PullRequest
 	var statusName string
 	var mergedAt pgxNullTime
-	err := p.pool.QueryRow(ctx, `
-        SELECT pr.id, pr.title, pr.author_id, st.name, pr.created_at, pr.merged_at
+	err := q.QueryRow(ctx, `
+        SELECT pr.id, pr.title, pr.author_id, st.name, pr.created_at, pr.merged_at, pr.tags, COALESCE(pr.merge_forced_by, ''), pr.source
         FROM pull_requests pr
         JOIN pr_statuses st ON pr.status_id = st.id
         WHERE pr.id=$1
-    `, prID).Scan(&pr.ID, &pr.Title, &pr.AuthorID, &statusName, &pr.CreatedAt, &mergedAt)
+    `, prID).Scan(&pr.ID, &pr.Title, &pr.AuthorID, &statusName, &pr.CreatedAt, &mergedAt, &pr.Tags, &pr.MergeForcedBy, &pr.Source)
 	if err != nil {
 		return pr, repository.ErrNotFound
 	}
-	pr.Status = statusName
+	pr.Status = domain.PRStatus(statusName)
 	if mergedAt.Valid {
 		t := mergedAt.Time
 		pr.MergedAt = &t
 	}
 
-	rows, err := p.pool.Query(ctx, "SELECT reviewer_id FROM pr_reviewers WHERE pr_id=$1", prID)
+	rows, err := q.Query(ctx, `
+        SELECT rv.reviewer_id, t.name
+        FROM pr_reviewers rv
+        JOIN users u ON u.id = rv.reviewer_id
+        JOIN teams t ON t.id = u.team_id
+        WHERE rv.pr_id=$1
+    `, prID)
 	if err == nil {
 		defer rows.Close()
 		var revs []string
+		teams := map[string]string{}
 		for rows.Next() {
-			var rid string
-			if err := rows.Scan(&rid); err != nil {
+			var rid, teamName string
+			if err := rows.Scan(&rid, &teamName); err != nil {
 				return pr, err
 			}
 			revs = append(revs, rid)
+			teams[rid] = teamName
 		}
 		pr.Reviewers = revs
+		pr.ReviewerTeams = teams
+	}
+	decisions, err := latestReviews(ctx, q, prID)
+	if err != nil {
+		return pr, err
+	}
+	if len(decisions) > 0 {
+		pr.ReviewDecisions = decisions
 	}
 	return pr, nil
 }
@@ -213,8 +441,15 @@ func (n *pgxNullTime) Scan(src interface{}) error {
 }
 
 func (p *PGRepo) GetActiveTeamMembersExcluding(ctx context.Context, teamID int, exclude []string) ([]domain.User, error) {
-	var users []domain.User
-	q := "SELECT id, username, team_id, is_active FROM users WHERE team_id=$1 AND is_active=TRUE"
+	// max_concurrent_reviews = 0 означает отсутствие ограничения;
+	// отсутствующие сейчас пользователи кандидатами не считаются
+	q := "SELECT " + userColumns + ` FROM users u JOIN teams t ON t.id = u.team_id
+        WHERE u.team_id=$1 AND u.is_active=TRUE
+        AND (u.max_concurrent_reviews = 0 OR u.max_concurrent_reviews > ` + openReviewsSubquery + `)
+        AND NOT EXISTS (
+            SELECT 1 FROM user_absences a
+            WHERE a.user_id = u.id AND a.starts_at <= now() AND a.ends_at > now()
+        )`
 	args := []interface{}{teamID}
 	if len(exclude) > 0 {
 		placeholders := make([]string, len(exclude))
@@ -222,21 +457,9 @@ func (p *PGRepo) GetActiveTeamMembersExcluding(ctx context.Context, teamID int,
 			placeholders[i] = fmt.Sprintf("$%d", i+2)
 			args = append(args, exclude[i])
 		}
-		q = q + " AND id NOT IN (" + strings.Join(placeholders, ",") + ")"
-	}
-	rows, err := p.pool.Query(ctx, q, args...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.TeamID, &u.IsActive); err != nil {
-			return nil, err
-		}
-		users = append(users, u)
+		q = q + " AND u.id NOT IN (" + strings.Join(placeholders, ",") + ")"
 	}
-	return users, nil
+	return p.queryUsers(ctx, q, args...)
 }
 
 func (p *PGRepo) GetPRReviewers(ctx context.Context, prID string) ([]string, error) {
@@ -276,10 +499,18 @@ func (p *PGRepo) GetPRAuthor(ctx context.Context, prID string) (string, error) {
 
 func (p *PGRepo) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error) {
 	rows, err := p.pool.Query(ctx, `
-        SELECT pr.id, pr.title, pr.author_id, st.name, pr.created_at, pr.merged_at
+        SELECT pr.id, pr.title, pr.author_id, st.name, pr.created_at, pr.merged_at, pr.tags,
+               lr.id, lr.decision, lr.comment, lr.created_at
         FROM pr_reviewers rv
         JOIN pull_requests pr ON pr.id = rv.pr_id
         JOIN pr_statuses st ON pr.status_id = st.id
+        LEFT JOIN LATERAL (
+            SELECT r.id, r.decision, r.comment, r.created_at
+            FROM pr_reviews r
+            WHERE r.pr_id = pr.id AND r.reviewer_id = rv.reviewer_id
+            ORDER BY r.created_at DESC, r.id DESC
+            LIMIT 1
+        ) lr ON TRUE
         WHERE rv.reviewer_id = $1
     `, userID)
 	if err != nil {
@@ -289,20 +520,37 @@ func (p *PGRepo) GetUserReviews(ctx context.Context, userID string) ([]domain.Pu
 	var prs []domain.PullRequest
 	for rows.Next() {
 		var pr domain.PullRequest
+		var status string
 		var merged pgxNullTime
-		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &merged); err != nil {
+		// последнее решение пользователя по PR, если оно есть
+		var reviewID *int
+		var decision, comment *string
+		var decidedAt pgxNullTime
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &status, &pr.CreatedAt, &merged, &pr.Tags,
+			&reviewID, &decision, &comment, &decidedAt); err != nil {
 			return nil, err
 		}
+		pr.Status = domain.PRStatus(status)
 		if merged.Valid {
 			t := merged.Time
 			pr.MergedAt = &t
 		}
+		if reviewID != nil {
+			pr.ReviewDecisions = map[string]domain.Review{userID: {
+				ID:            *reviewID,
+				PullRequestID: pr.ID,
+				ReviewerID:    userID,
+				Decision:      domain.ReviewDecision(*decision),
+				Comment:       *comment,
+				CreatedAt:     decidedAt.Time,
+			}}
+		}
 		prs = append(prs, pr)
 	}
 	return prs, rows.Err()
 }
 
-func (p *PGRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
+func (p *PGRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string, reason domain.AssignmentReason) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -311,70 +559,45 @@ func (p *PGRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUser
 		_ = tx.Rollback(ctx)
 	}()
 
-	var status string
-	err = tx.QueryRow(ctx, `
-        SELECT st.name
-        FROM pull_requests pr
-        JOIN pr_statuses st ON pr.status_id = st.id
-        WHERE pr.id=$1
-        FOR UPDATE
-    `, prID).Scan(&status)
+	state, err := lockPRState(ctx, tx, prID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return repository.ErrNotFound
-		}
 		return err
 	}
-	if status == "MERGED" {
-		return repository.ErrPRMerged
-	}
-
-	cmd, err := tx.Exec(ctx, "DELETE FROM pr_reviewers WHERE pr_id=$1 AND reviewer_id=$2", prID, oldUserID)
-	if err != nil {
+	if err := reviewersLockedErr(string(state.PR.Status)); err != nil {
 		return err
 	}
-	if cmd.RowsAffected() == 0 {
+	if !state.IsAssigned(oldUserID) {
 		return repository.ErrNotAssigned
 	}
 
-	// Проверяем, есть ли у старого ревьювера другие открытые PR
-	var hasOpenPRs bool
-	err = tx.QueryRow(ctx, `
-		SELECT EXISTS(
-			SELECT 1 
-			FROM pr_reviewers rv
-			JOIN pull_requests pr ON pr.id = rv.pr_id
-			JOIN pr_statuses st ON pr.status_id = st.id
-			WHERE rv.reviewer_id = $1 AND st.name = 'OPEN'
-		)
-	`, oldUserID).Scan(&hasOpenPRs)
-	if err != nil {
+	r := domain.Replacement{PullRequestID: prID, OldReviewerID: oldUserID, NewReviewerID: newUserID}
+	if err := savePREvents(ctx, tx, &state, domain.NewPRReviewerReplaced(r)); err != nil {
 		return err
 	}
-
-	// Активируем старого ревьювера, если у него нет других открытых PR
-	if !hasOpenPRs {
-		_, err = tx.Exec(ctx, "UPDATE users SET is_active=TRUE WHERE id=$1", oldUserID)
-		if err != nil {
-			return err
-		}
-	}
-
-	// Добавляем нового ревьювера
-	if _, err := tx.Exec(ctx, "INSERT INTO pr_reviewers (pr_id, reviewer_id) VALUES ($1,$2)", prID, newUserID); err != nil {
+	if err := insertEvents(ctx, tx, domain.NewReviewerReassignedEvent(r)); err != nil {
 		return err
 	}
-
-	// Деактивируем нового ревьювера
-	_, err = tx.Exec(ctx, "UPDATE users SET is_active=FALSE WHERE id=$1", newUserID)
-	if err != nil {
+	a := domain.AssignmentEvent{PullRequestID: prID, OldReviewerID: oldUserID, NewReviewerID: newUserID, Reason: reason}
+	if err := insertAssignmentEvents(ctx, tx, a); err != nil {
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
-func (p *PGRepo) MergePR(ctx context.Context, prID string) error {
+// reviewersLockedErr возвращает ошибку, если ревьюверов PR в статусе status менять нельзя
+func reviewersLockedErr(status string) error {
+	s := domain.PRStatus(status)
+	switch {
+	case s == domain.StatusMerged:
+		return repository.ErrPRMerged
+	case !s.CanChangeReviewers():
+		return repository.ErrPRClosed
+	}
+	return nil
+}
+
+func (p *PGRepo) TransitionPR(ctx context.Context, prID string, to domain.PRStatus) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -383,31 +606,23 @@ func (p *PGRepo) MergePR(ctx context.Context, prID string) error {
 		_ = tx.Rollback(ctx)
 	}()
 
-	var status string
-	err = tx.QueryRow(ctx, "SELECT st.name FROM pull_requests pr JOIN pr_statuses st ON pr.status_id=st.id WHERE pr.id=$1 FOR UPDATE", prID).Scan(&status)
+	state, err := lockPRState(ctx, tx, prID)
 	if err != nil {
-		return repository.ErrNotFound
+		return err
 	}
-	if status == "MERGED" {
+	from := state.PR.Status
+	if from == to {
 		return tx.Commit(ctx)
 	}
-
-	_, err = tx.Exec(ctx, "UPDATE pull_requests SET status_id = (SELECT id FROM pr_statuses WHERE name='MERGED'), merged_at=$2 WHERE id=$1", prID, time.Now().UTC())
-	if err != nil {
-		return err
+	if !domain.CanTransition(from, to) {
+		return repository.ErrInvalidTransition
 	}
 
-	// Активируем всех ревьюверов после merge
-	_, err = tx.Exec(ctx, `
-		UPDATE users 
-		SET is_active=TRUE 
-		WHERE id IN (
-			SELECT reviewer_id 
-			FROM pr_reviewers 
-			WHERE pr_id=$1
-		)
-	`, prID)
-	if err != nil {
+	e := domain.NewPRStatusChanged(prID, to)
+	if to == domain.StatusMerged {
+		e = domain.NewPRMerged(prID, "")
+	}
+	if err := savePREvents(ctx, tx, &state, e); err != nil {
 		return err
 	}
 
@@ -422,7 +637,7 @@ func (p *PGRepo) HasOpenPRsAsReviewer(ctx context.Context, userID string) (bool,
 			FROM pr_reviewers rv
 			JOIN pull_requests pr ON pr.id = rv.pr_id
 			JOIN pr_statuses st ON pr.status_id = st.id
-			WHERE rv.reviewer_id = $1 AND st.name = 'OPEN'
+			WHERE rv.reviewer_id = $1 AND st.name IN ('OPEN', 'REOPENED')
 		)
 	`, userID).Scan(&hasOpen)
 	return hasOpen, err
@@ -430,9 +645,15 @@ func (p *PGRepo) HasOpenPRsAsReviewer(ctx context.Context, userID string) (bool,
 
 func (p *PGRepo) GetReviewerStats(ctx context.Context) ([]repository.ReviewerStat, error) {
 	rows, err := p.pool.Query(ctx, `
-		SELECT u.id, u.username, COUNT(rv.pr_id) as assignment_count
+		SELECT u.id, u.username, COUNT(rv.pr_id) as assignment_count, COUNT(fr.first_at),
+			COALESCE(EXTRACT(EPOCH FROM AVG(fr.first_at - rv.assigned_at)), 0)::float8
 		FROM users u
 		LEFT JOIN pr_reviewers rv ON u.id = rv.reviewer_id
+		LEFT JOIN LATERAL (
+			SELECT MIN(r.created_at) AS first_at
+			FROM pr_reviews r
+			WHERE r.pr_id = rv.pr_id AND r.reviewer_id = rv.reviewer_id AND r.created_at >= rv.assigned_at
+		) fr ON TRUE
 		GROUP BY u.id, u.username
 		ORDER BY assignment_count DESC, u.username
 	`)
@@ -443,10 +664,55 @@ func (p *PGRepo) GetReviewerStats(ctx context.Context) ([]repository.ReviewerSta
 	var stats []repository.ReviewerStat
 	for rows.Next() {
 		var stat repository.ReviewerStat
-		if err := rows.Scan(&stat.UserID, &stat.Username, &stat.Count); err != nil {
+		var avgSeconds float64
+		if err := rows.Scan(&stat.UserID, &stat.Username, &stat.Count, &stat.ReviewedCount, &avgSeconds); err != nil {
 			return nil, err
 		}
+		stat.AvgTimeToFirstReview = time.Duration(avgSeconds * float64(time.Second))
 		stats = append(stats, stat)
 	}
 	return stats, rows.Err()
 }
+
+func (p *PGRepo) GetOverduePRs(ctx context.Context, now time.Time) ([]domain.OverduePR, error) {
+	rows, err := p.pool.Query(ctx, `
+        SELECT pr.id, pr.title, pr.author_id, st.name, rv.reviewer_id, rv.assigned_at,
+               rv.assigned_at + make_interval(hours => t.review_sla_hours) AS due_at
+        FROM pull_requests pr
+        JOIN pr_statuses st ON pr.status_id = st.id
+        JOIN users a ON a.id = pr.author_id
+        JOIN teams t ON t.id = a.team_id
+        JOIN pr_reviewers rv ON rv.pr_id = pr.id
+        WHERE st.name IN ('OPEN', 'REOPENED')
+          AND t.review_sla_hours > 0
+          AND rv.assigned_at + make_interval(hours => t.review_sla_hours) < $1
+          AND NOT EXISTS (
+              SELECT 1 FROM pr_reviews r
+              WHERE r.pr_id = rv.pr_id AND r.reviewer_id = rv.reviewer_id AND r.created_at >= rv.assigned_at
+          )
+        ORDER BY due_at, pr.id, rv.reviewer_id
+    `, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.OverduePR{}
+	index := map[string]int{}
+	for rows.Next() {
+		var pr domain.OverduePR
+		var status string
+		var r domain.OverdueReview
+		if err := rows.Scan(&pr.ID, &pr.Title, &pr.AuthorID, &status, &r.ReviewerID, &r.AssignedAt, &r.DueAt); err != nil {
+			return nil, err
+		}
+		i, ok := index[pr.ID]
+		if !ok {
+			pr.Status = domain.PRStatus(status)
+			res = append(res, pr)
+			i = len(res) - 1
+			index[pr.ID] = i
+		}
+		res[i].Reviewers = append(res[i].Reviewers, r)
+	}
+	return res, rows.Err()
+}
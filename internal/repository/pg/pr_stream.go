@@ -0,0 +1,190 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// lockPRState блокирует PR до конца транзакции tx и восстанавливает его состояние из потока событий
+func lockPRState(ctx context.Context, tx pgx.Tx, prID string) (domain.PRState, error) {
+	var id string
+	err := tx.QueryRow(ctx, "SELECT id FROM pull_requests WHERE id=$1 FOR UPDATE", prID).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.PRState{}, repository.ErrNotFound
+		}
+		return domain.PRState{}, err
+	}
+	return loadPRState(ctx, tx, prID)
+}
+
+func loadPRState(ctx context.Context, q querier, prID string) (domain.PRState, error) {
+	events, err := loadPREvents(ctx, q, prID)
+	if err != nil {
+		return domain.PRState{}, err
+	}
+	state, err := domain.ReplayPR(events)
+	if err != nil {
+		return state, err
+	}
+	if !state.Exists() {
+		return state, repository.ErrNotFound
+	}
+	return state, nil
+}
+
+func (p *PGRepo) GetPRAt(ctx context.Context, prID string, at time.Time) (domain.PullRequest, error) {
+	events, err := loadPREvents(ctx, p.pool, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	state, err := domain.ReplayPRUntil(events, at)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	if !state.Exists() {
+		return domain.PullRequest{}, repository.ErrNotFound
+	}
+	if !state.HistoryKnownAt(at) {
+		return domain.PullRequest{}, repository.ErrHistoryUnavailable
+	}
+	return state.PR, nil
+}
+
+func loadPREvents(ctx context.Context, q querier, prID string) ([]domain.PREvent, error) {
+	rows, err := q.Query(ctx, `
+        SELECT pr_id, version, type, data, occurred_at
+        FROM pr_events
+        WHERE pr_id=$1
+        ORDER BY version
+    `, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []domain.PREvent
+	for rows.Next() {
+		var e domain.PREvent
+		var data []byte
+		if err := rows.Scan(&e.PullRequestID, &e.Version, &e.Type, &data, &e.OccurredAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &e.Data); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// savePREvents применяет события к state, дописывает их в поток PR и обновляет проекции.
+// Повторная запись той же версии (гонка без блокировки PR) нарушит первичный ключ pr_events.
+func savePREvents(ctx context.Context, tx pgx.Tx, state *domain.PRState, events ...domain.PREvent) error {
+	now := time.Now().UTC()
+	for _, e := range events {
+		e.Version = state.Version + 1
+		e.OccurredAt = now
+		if err := state.Apply(e); err != nil {
+			return err
+		}
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+            INSERT INTO pr_events (pr_id, version, type, data, occurred_at)
+            VALUES ($1,$2,$3,$4::jsonb,$5)
+        `, e.PullRequestID, e.Version, e.Type, string(data), e.OccurredAt)
+		if err != nil {
+			return err
+		}
+	}
+	return writePRProjection(ctx, tx, *state)
+}
+
+// writePRProjection записывает состояние PR в pull_requests и pr_reviewers
+func writePRProjection(ctx context.Context, tx pgx.Tx, s domain.PRState) error {
+	pr := s.PR
+	_, err := tx.Exec(ctx, `
+        INSERT INTO pull_requests (id, title, author_id, status_id, created_at, merged_at, tags, merge_forced_by, source)
+        VALUES ($1,$2,$3,(SELECT id FROM pr_statuses WHERE name=$4),$5,$6,COALESCE($7::text[], '{}'),NULLIF($8,''),$9)
+        ON CONFLICT (id) DO UPDATE SET
+            title=EXCLUDED.title, author_id=EXCLUDED.author_id, status_id=EXCLUDED.status_id,
+            created_at=EXCLUDED.created_at, merged_at=EXCLUDED.merged_at, tags=EXCLUDED.tags,
+            merge_forced_by=EXCLUDED.merge_forced_by, source=EXCLUDED.source
+    `, pr.ID, pr.Title, pr.AuthorID, string(pr.Status), pr.CreatedAt, pr.MergedAt, pr.Tags, pr.MergeForcedBy, pr.Source)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM pr_reviewers WHERE pr_id=$1", pr.ID); err != nil {
+		return err
+	}
+	for _, a := range s.Assignments {
+		_, err := tx.Exec(ctx, "INSERT INTO pr_reviewers (pr_id, reviewer_id, assigned_at, escalated_at) VALUES ($1,$2,$3,$4)",
+			pr.ID, a.ReviewerID, a.AssignedAt, a.EscalatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RebuildPRProjections пересобирает pull_requests и pr_reviewers из потоков событий и
+// возвращает число пересобранных PR. Каждый PR пересобирается в своей транзакции под
+// блокировкой, поэтому сервис может работать во время пересборки.
+func (p *PGRepo) RebuildPRProjections(ctx context.Context) (int, error) {
+	rows, err := p.pool.Query(ctx, "SELECT DISTINCT pr_id FROM pr_events ORDER BY pr_id")
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		if err := p.rebuildPRProjection(ctx, id); err != nil {
+			return i, fmt.Errorf("PR %s: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+func (p *PGRepo) rebuildPRProjection(ctx context.Context, prID string) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	state, err := lockPRState(ctx, tx, prID)
+	if err == repository.ErrNotFound {
+		// строки проекции нет - её и нужно восстановить
+		state, err = loadPRState(ctx, tx, prID)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writePRProjection(ctx, tx, state); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,144 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+func (p *PGRepo) AddReview(ctx context.Context, r domain.Review) (domain.Review, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var status string
+	err = tx.QueryRow(ctx, `
+        SELECT st.name
+        FROM pull_requests pr
+        JOIN pr_statuses st ON pr.status_id = st.id
+        WHERE pr.id=$1
+        FOR UPDATE
+    `, r.PullRequestID).Scan(&status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Review{}, repository.ErrNotFound
+		}
+		return domain.Review{}, err
+	}
+	if err := reviewersLockedErr(status); err != nil {
+		return domain.Review{}, err
+	}
+
+	var assigned bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pr_id=$1 AND reviewer_id=$2)",
+		r.PullRequestID, r.ReviewerID).Scan(&assigned)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	if !assigned {
+		return domain.Review{}, repository.ErrNotAssigned
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO pr_reviews (pr_id, reviewer_id, decision, comment)
+        VALUES ($1,$2,$3,$4)
+        RETURNING id, created_at
+    `, r.PullRequestID, r.ReviewerID, string(r.Decision), r.Comment).Scan(&r.ID, &r.CreatedAt)
+	if err != nil {
+		return domain.Review{}, err
+	}
+	return r, tx.Commit(ctx)
+}
+
+// querier - общее у пула и транзакции, чтобы читать PR и решения и внутри транзакции
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// latestReviews возвращает последнее решение каждого назначенного ревьювера PR
+func latestReviews(ctx context.Context, q querier, prID string) (map[string]domain.Review, error) {
+	rows, err := q.Query(ctx, `
+        SELECT DISTINCT ON (r.reviewer_id) r.id, r.pr_id, r.reviewer_id, r.decision, r.comment, r.created_at
+        FROM pr_reviews r
+        JOIN pr_reviewers rv ON rv.pr_id = r.pr_id AND rv.reviewer_id = r.reviewer_id
+        WHERE r.pr_id=$1
+        ORDER BY r.reviewer_id, r.created_at DESC, r.id DESC
+    `, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[string]domain.Review{}
+	for rows.Next() {
+		var r domain.Review
+		var decision string
+		if err := rows.Scan(&r.ID, &r.PullRequestID, &r.ReviewerID, &decision, &r.Comment, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		r.Decision = domain.ReviewDecision(decision)
+		res[r.ReviewerID] = r
+	}
+	return res, rows.Err()
+}
+
+func (p *PGRepo) MergePR(ctx context.Context, prID, forcedBy string) ([]string, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	state, err := lockPRState(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	var team domain.Team
+	err = tx.QueryRow(ctx, `
+        SELECT t.required_approvals, t.allow_changes_requested
+        FROM users u
+        JOIN teams t ON t.id = u.team_id
+        WHERE u.id=$1
+    `, state.PR.AuthorID).Scan(&team.RequiredApprovals, &team.AllowChangesRequested)
+	if err != nil {
+		return nil, err
+	}
+	from := state.PR.Status
+	if from == domain.StatusMerged {
+		return nil, tx.Commit(ctx)
+	}
+	if !domain.CanTransition(from, domain.StatusMerged) {
+		return nil, repository.ErrInvalidTransition
+	}
+
+	if forcedBy == "" {
+		decisions, err := latestReviews(ctx, tx, prID)
+		if err != nil {
+			return nil, err
+		}
+		if unmet := team.MergeBlockers(decisions); len(unmet) > 0 {
+			return unmet, nil
+		}
+	}
+
+	if err := savePREvents(ctx, tx, &state, domain.NewPRMerged(prID, forcedBy)); err != nil {
+		return nil, err
+	}
+	pr, err := getPR(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if err := insertEvents(ctx, tx, domain.NewEvent(domain.EventPRMerged, pr)); err != nil {
+		return nil, err
+	}
+	return nil, tx.Commit(ctx)
+}
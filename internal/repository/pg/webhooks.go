@@ -0,0 +1,51 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+func (p *PGRepo) AddWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error) {
+	err := p.pool.QueryRow(ctx, `
+        INSERT INTO webhook_subscriptions (url, secret, events)
+        VALUES ($1, $2, COALESCE($3::text[], '{}'))
+        RETURNING id, created_at
+    `, w.URL, w.Secret, w.Events).Scan(&w.ID, &w.CreatedAt)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if w.Events == nil {
+		w.Events = []string{}
+	}
+	return w, nil
+}
+
+func (p *PGRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := p.pool.Query(ctx, "SELECT id, url, secret, events, created_at FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []domain.Webhook{}
+	for rows.Next() {
+		var w domain.Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, w)
+	}
+	return res, rows.Err()
+}
+
+func (p *PGRepo) DeleteWebhook(ctx context.Context, webhookID int) error {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM webhook_subscriptions WHERE id=$1", webhookID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
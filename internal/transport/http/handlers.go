@@ -1,11 +1,20 @@
 package http
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/mail"
+	"strings"
+	"time"
 
+	"github.com/you/pr-assign-avito/internal/codeowners"
 	"github.com/you/pr-assign-avito/internal/domain"
 	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/integration/github"
+	"github.com/you/pr-assign-avito/internal/integration/gitlab"
 	"github.com/you/pr-assign-avito/internal/repository"
 	uc "github.com/you/pr-assign-avito/internal/usecase"
 )
@@ -14,34 +23,85 @@ const (
 	codeTeamExists  = "TEAM_EXISTS"
 	codePRExists    = "PR_EXISTS"
 	codePRMerged    = "PR_MERGED"
+	codePRClosed    = "PR_CLOSED"
 	codeNotAssigned = "NOT_ASSIGNED"
 	codeNoCandidate = "NO_CANDIDATE"
 	codeNotFound    = "NOT_FOUND"
 	codeValidation  = "VALIDATION_ERROR"
+
+	codeNotEnoughReviewers = "NOT_ENOUGH_REVIEWERS"
+	codeInvalidTransition  = "INVALID_TRANSITION"
+	codeMergeBlocked       = "MERGE_BLOCKED"
+	codeForbidden          = "FORBIDDEN"
+	codeUnauthorized       = "UNAUTHORIZED"
+	codeUnknownLogin       = "UNKNOWN_LOGIN"
+	codeHistoryUnavailable = "HISTORY_UNAVAILABLE"
 )
 
+// adminTokenHeader - заголовок с токеном администратора для merge в обход политики
+const adminTokenHeader = "X-Admin-Token"
+
 type Handlers struct {
 	UC   *uc.PRUsecase
 	Repo repository.Repo
 	Log  infra.Logger
+	// AdminToken разрешает force merge; пустой токен запрещает его
+	AdminToken string
+
+	GitHub *github.Ingestor
+	// GitHubSecret - секрет вебхука GitHub; пустой секрет отключает приём вебхуков
+	GitHubSecret string
+
+	GitLab *gitlab.Ingestor
+	// GitLabToken - секретный токен вебхука GitLab; пустой токен отключает приём вебхуков
+	GitLabToken string
 }
 
 type apiTeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID               string   `json:"user_id"`
+	Username             string   `json:"username"`
+	IsActive             bool     `json:"is_active"`
+	MaxConcurrentReviews int      `json:"max_concurrent_reviews"`
+	OpenReviews          int      `json:"open_reviews"`
+	Tags                 []string `json:"tags"`
+	ChatHandle           string   `json:"chat_handle,omitempty"`
+	Email                string   `json:"email,omitempty"`
+	DigestOptOut         bool     `json:"digest_opt_out"`
 }
 
 type apiTeam struct {
-	TeamName string          `json:"team_name"`
-	Members  []apiTeamMember `json:"members"`
+	TeamName         string          `json:"team_name"`
+	ReviewerStrategy string          `json:"reviewer_strategy,omitempty"`
+	MinReviewers     int             `json:"min_reviewers"`
+	MaxReviewers     int             `json:"max_reviewers"`
+	FallbackTeams    []string        `json:"fallback_teams"`
+	Members          []apiTeamMember `json:"members"`
+
+	// политика merge
+	RequiredApprovals     int  `json:"required_approvals"`
+	AllowChangesRequested bool `json:"allow_changes_requested"`
+	ReviewSLAHours        int  `json:"review_sla_hours"`
+
+	// эскалация зависших ревью
+	EscalationHours  int    `json:"escalation_hours"`
+	EscalationAction string `json:"escalation_action"`
+
+	// уведомления в чат; сам URL вебхука не возвращается, он даёт право писать в канал
+	ChatWebhookConfigured bool   `json:"chat_webhook_configured"`
+	ChatChannel           string `json:"chat_channel,omitempty"`
 }
 
 type apiUser struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	UserID               string   `json:"user_id"`
+	Username             string   `json:"username"`
+	TeamName             string   `json:"team_name"`
+	IsActive             bool     `json:"is_active"`
+	MaxConcurrentReviews int      `json:"max_concurrent_reviews"`
+	OpenReviews          int      `json:"open_reviews"`
+	Tags                 []string `json:"tags"`
+	ChatHandle           string   `json:"chat_handle,omitempty"`
+	Email                string   `json:"email,omitempty"`
+	DigestOptOut         bool     `json:"digest_opt_out"`
 }
 
 type apiPullRequestShort struct {
@@ -49,10 +109,19 @@ type apiPullRequestShort struct {
 	Name     string `json:"pull_request_name"`
 	AuthorID string `json:"author_id"`
 	Status   string `json:"status"`
+	// последнее решение пользователя по PR
+	Decision  string     `json:"decision,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
 }
 
 func NewHandlers(uc *uc.PRUsecase, repo repository.Repo, log infra.Logger) *Handlers {
-	return &Handlers{UC: uc, Repo: repo, Log: log}
+	return &Handlers{
+		UC:     uc,
+		Repo:   repo,
+		Log:    log,
+		GitHub: github.NewIngestor(uc, repo),
+		GitLab: gitlab.NewIngestor(uc, repo),
+	}
 }
 
 func writeJSON(w http.ResponseWriter, code int, v interface{}) {
@@ -84,12 +153,34 @@ func (h *Handlers) Health(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handlers) AddTeam(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		TeamName string `json:"team_name"`
-		Members  []struct {
-			UserID   string `json:"user_id"`
-			Username string `json:"username"`
-			IsActive bool   `json:"is_active"`
+		TeamName         string   `json:"team_name"`
+		ReviewerStrategy string   `json:"reviewer_strategy"`
+		MinReviewers     int      `json:"min_reviewers"`
+		MaxReviewers     int      `json:"max_reviewers"`
+		FallbackTeams    []string `json:"fallback_teams"`
+		Members          []struct {
+			UserID               string   `json:"user_id"`
+			Username             string   `json:"username"`
+			IsActive             bool     `json:"is_active"`
+			MaxConcurrentReviews *int     `json:"max_concurrent_reviews"`
+			Tags                 []string `json:"tags"`
+			ChatHandle           *string  `json:"chat_handle"`
+			Email                *string  `json:"email"`
+			DigestOptOut         *bool    `json:"digest_opt_out"`
 		} `json:"members"`
+
+		// политика merge
+		RequiredApprovals     int  `json:"required_approvals"`
+		AllowChangesRequested bool `json:"allow_changes_requested"`
+		ReviewSLAHours        int  `json:"review_sla_hours"`
+
+		// эскалация зависших ревью
+		EscalationHours  int    `json:"escalation_hours"`
+		EscalationAction string `json:"escalation_action"`
+
+		// уведомления в чат
+		ChatWebhookURL string `json:"chat_webhook_url"`
+		ChatChannel    string `json:"chat_channel"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		h.Log.Errorf("AddTeam: failed to decode request body: %v", err)
@@ -100,30 +191,81 @@ func (h *Handlers) AddTeam(w http.ResponseWriter, r *http.Request) {
 		badRequest(w, "team_name required")
 		return
 	}
-	users := make([]domain.User, 0, len(payload.Members))
+	team := domain.Team{
+		Name:             payload.TeamName,
+		ReviewerStrategy: payload.ReviewerStrategy,
+		MinReviewers:     payload.MinReviewers,
+		MaxReviewers:     payload.MaxReviewers,
+		FallbackTeams:    payload.FallbackTeams,
+
+		RequiredApprovals:     payload.RequiredApprovals,
+		AllowChangesRequested: payload.AllowChangesRequested,
+		ReviewSLAHours:        payload.ReviewSLAHours,
+
+		EscalationHours:  payload.EscalationHours,
+		EscalationAction: domain.EscalationAction(payload.EscalationAction),
+
+		ChatWebhookURL: payload.ChatWebhookURL,
+		ChatChannel:    payload.ChatChannel,
+	}
+	if msg := validateTeamSettings(team); msg != "" {
+		badRequest(w, msg)
+		return
+	}
+	// непереданные настройки участника остаются nil и не затирают уже сохранённые
+	users := make([]domain.TeamMember, 0, len(payload.Members))
 	for _, m := range payload.Members {
 		if m.UserID == "" || m.Username == "" {
 			badRequest(w, "member user_id and username required")
 			return
 		}
-		users = append(users, domain.User{ID: m.UserID, Username: m.Username, IsActive: m.IsActive})
+		if m.MaxConcurrentReviews != nil && *m.MaxConcurrentReviews < 0 {
+			badRequest(w, "max_concurrent_reviews must not be negative")
+			return
+		}
+		var tags []string
+		if m.Tags != nil {
+			var ok bool
+			if tags, ok = domain.NormalizeTags(m.Tags); !ok {
+				badRequest(w, "tags must not be empty")
+				return
+			}
+		}
+		if m.Email != nil && *m.Email != "" && !isEmail(*m.Email) {
+			badRequest(w, "invalid email "+*m.Email)
+			return
+		}
+		users = append(users, domain.TeamMember{
+			ID:                   m.UserID,
+			Username:             m.Username,
+			IsActive:             m.IsActive,
+			MaxConcurrentReviews: m.MaxConcurrentReviews,
+			Tags:                 tags,
+			ChatHandle:           m.ChatHandle,
+			Email:                m.Email,
+			DigestOptOut:         m.DigestOptOut,
+		})
 	}
-	if err := h.Repo.CreateTeamWithMembers(r.Context(), payload.TeamName, users); err != nil {
+	if err := h.Repo.CreateTeamWithMembers(r.Context(), team, users); err != nil {
 		if err == repository.ErrTeamExists {
 			errorResp(w, http.StatusBadRequest, codeTeamExists, payload.TeamName+" already exists")
 			return
 		}
+		if err == repository.ErrUnknownTeam {
+			badRequest(w, "fallback team not found")
+			return
+		}
 		h.Log.Errorf("AddTeam: failed to create team: %v", err)
 		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
 		return
 	}
-	team, members, err := h.Repo.GetTeamByName(r.Context(), payload.TeamName)
+	created, members, err := h.Repo.GetTeamByName(r.Context(), payload.TeamName)
 	if err != nil {
 		h.Log.Errorf("AddTeam: failed to get team after creation: %v", err)
 		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
 		return
 	}
-	apiTeamResp := buildAPITeam(team, members)
+	apiTeamResp := buildAPITeam(created, members)
 	resp := struct {
 		Team apiTeam `json:"team"`
 	}{Team: apiTeamResp}
@@ -149,10 +291,175 @@ func (h *Handlers) GetTeam(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, buildAPITeam(team, users))
 }
 
+func (h *Handlers) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TeamName         string    `json:"team_name"`
+		ReviewerStrategy *string   `json:"reviewer_strategy"`
+		MinReviewers     *int      `json:"min_reviewers"`
+		MaxReviewers     *int      `json:"max_reviewers"`
+		FallbackTeams    *[]string `json:"fallback_teams"`
+
+		RequiredApprovals     *int  `json:"required_approvals"`
+		AllowChangesRequested *bool `json:"allow_changes_requested"`
+		ReviewSLAHours        *int  `json:"review_sla_hours"`
+
+		EscalationHours  *int    `json:"escalation_hours"`
+		EscalationAction *string `json:"escalation_action"`
+
+		ChatWebhookURL *string `json:"chat_webhook_url"`
+		ChatChannel    *string `json:"chat_channel"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("UpdateTeam: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.TeamName == "" {
+		badRequest(w, "team_name required")
+		return
+	}
+	team, _, err := h.Repo.GetTeamByName(r.Context(), payload.TeamName)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "team not found")
+			return
+		}
+		h.Log.Errorf("UpdateTeam: failed to get team: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	if payload.ReviewerStrategy != nil {
+		team.ReviewerStrategy = *payload.ReviewerStrategy
+	}
+	if payload.MinReviewers != nil {
+		team.MinReviewers = *payload.MinReviewers
+	}
+	if payload.MaxReviewers != nil {
+		team.MaxReviewers = *payload.MaxReviewers
+	}
+	if payload.FallbackTeams != nil {
+		team.FallbackTeams = *payload.FallbackTeams
+	}
+	if payload.RequiredApprovals != nil {
+		team.RequiredApprovals = *payload.RequiredApprovals
+	}
+	if payload.AllowChangesRequested != nil {
+		team.AllowChangesRequested = *payload.AllowChangesRequested
+	}
+	if payload.ReviewSLAHours != nil {
+		team.ReviewSLAHours = *payload.ReviewSLAHours
+	}
+	if payload.EscalationHours != nil {
+		team.EscalationHours = *payload.EscalationHours
+	}
+	if payload.EscalationAction != nil {
+		team.EscalationAction = domain.EscalationAction(*payload.EscalationAction)
+	}
+	if payload.ChatWebhookURL != nil {
+		team.ChatWebhookURL = *payload.ChatWebhookURL
+	}
+	if payload.ChatChannel != nil {
+		team.ChatChannel = *payload.ChatChannel
+	}
+	if msg := validateTeamSettings(team); msg != "" {
+		badRequest(w, msg)
+		return
+	}
+	if err := h.Repo.UpdateTeam(r.Context(), team); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "team not found")
+			return
+		}
+		if err == repository.ErrUnknownTeam {
+			badRequest(w, "fallback team not found")
+			return
+		}
+		h.Log.Errorf("UpdateTeam: failed to update team: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	updated, members, err := h.Repo.GetTeamByName(r.Context(), payload.TeamName)
+	if err != nil {
+		h.Log.Errorf("UpdateTeam: failed to get team after update: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team": buildAPITeam(updated, members)})
+}
+
+func (h *Handlers) SetCodeowners(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TeamName   string `json:"team_name"`
+		Codeowners string `json:"codeowners"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("SetCodeowners: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.TeamName == "" {
+		badRequest(w, "team_name required")
+		return
+	}
+	rules, err := codeowners.Parse(payload.Codeowners)
+	if err != nil {
+		badRequest(w, "invalid codeowners: "+err.Error())
+		return
+	}
+	if err := h.Repo.SetTeamCodeowners(r.Context(), payload.TeamName, payload.Codeowners); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "team not found")
+			return
+		}
+		h.Log.Errorf("SetCodeowners: failed to set codeowners: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"team_name": payload.TeamName, "rules": len(rules.Rules)})
+}
+
+func (h *Handlers) DeactivateTeamUsers(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		TeamName string   `json:"team_name"`
+		UserIDs  []string `json:"user_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("DeactivateTeamUsers: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.TeamName == "" || len(payload.UserIDs) == 0 {
+		badRequest(w, "team_name and user_ids required")
+		return
+	}
+	result, err := h.UC.DeactivateTeamUsers(r.Context(), payload.TeamName, payload.UserIDs)
+	if err != nil {
+		switch err {
+		case uc.ErrNotFound:
+			notFound(w, "team or user not found")
+		case uc.ErrNotTeamMember:
+			badRequest(w, "all user_ids must be members of the team")
+		case uc.ErrPRMerged:
+			errorResp(w, http.StatusConflict, codePRMerged, "PR was merged during deactivation, retry the request")
+		case uc.ErrPRClosed:
+			errorResp(w, http.StatusConflict, codePRClosed, "PR was closed during deactivation, retry the request")
+		case uc.ErrNotAssigned:
+			errorResp(w, http.StatusConflict, codeNotAssigned, "reviewers changed during deactivation, retry the request")
+		default:
+			h.Log.Errorf("DeactivateTeamUsers: failed to deactivate users: %v", err)
+			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": result})
+}
+
 func (h *Handlers) SetIsActive(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
 		UserID   string `json:"user_id"`
 		IsActive bool   `json:"is_active"`
+		// переназначить открытые ревью пользователя при деактивации
+		ReassignOpenReviews bool `json:"reassign_open_reviews"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		h.Log.Errorf("SetIsActive: failed to decode request body: %v", err)
@@ -173,9 +480,349 @@ func (h *Handlers) SetIsActive(w http.ResponseWriter, r *http.Request) {
 		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
 		return
 	}
+	resp := map[string]interface{}{"user": buildAPIUser(user)}
+	if !payload.IsActive && payload.ReassignOpenReviews {
+		report, err := h.UC.ReassignOpenReviews(r.Context(), user.ID)
+		if err != nil {
+			h.Log.Errorf("SetIsActive: failed to reassign open reviews: %v", err)
+			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+			return
+		}
+		resp["reassignment"] = report
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handlers) SetMaxReviews(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID               string `json:"user_id"`
+		MaxConcurrentReviews *int   `json:"max_concurrent_reviews"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("SetMaxReviews: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.UserID == "" || payload.MaxConcurrentReviews == nil {
+		badRequest(w, "user_id and max_concurrent_reviews required")
+		return
+	}
+	if *payload.MaxConcurrentReviews < 0 {
+		badRequest(w, "max_concurrent_reviews must not be negative")
+		return
+	}
+	user, err := h.Repo.SetUserMaxReviews(r.Context(), payload.UserID, *payload.MaxConcurrentReviews)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("SetMaxReviews: failed to set max reviews: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": buildAPIUser(user)})
+}
+
+func (h *Handlers) SetChatHandle(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID     string  `json:"user_id"`
+		ChatHandle *string `json:"chat_handle"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("SetChatHandle: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.UserID == "" || payload.ChatHandle == nil {
+		badRequest(w, "user_id and chat_handle required")
+		return
+	}
+	user, err := h.Repo.SetUserChatHandle(r.Context(), payload.UserID, strings.TrimSpace(*payload.ChatHandle))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("SetChatHandle: failed to set chat handle: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": buildAPIUser(user)})
+}
+
+func (h *Handlers) SetDigest(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID       string  `json:"user_id"`
+		Email        *string `json:"email"`
+		DigestOptOut *bool   `json:"digest_opt_out"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("SetDigest: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.UserID == "" || (payload.Email == nil && payload.DigestOptOut == nil) {
+		badRequest(w, "user_id and email or digest_opt_out required")
+		return
+	}
+	user, err := h.Repo.GetUserByID(r.Context(), payload.UserID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("SetDigest: failed to get user: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	if payload.Email != nil {
+		user.Email = strings.TrimSpace(*payload.Email)
+		if user.Email != "" && !isEmail(user.Email) {
+			badRequest(w, "invalid email")
+			return
+		}
+	}
+	if payload.DigestOptOut != nil {
+		user.DigestOptOut = *payload.DigestOptOut
+	}
+	user, err = h.Repo.SetUserDigest(r.Context(), user.ID, user.Email, user.DigestOptOut)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("SetDigest: failed to update user: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user": buildAPIUser(user)})
+}
+
+// isEmail сообщает, что s - голый адрес вида user@host без имени и скобок
+func isEmail(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s
+}
+
+func (h *Handlers) SetTags(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID string    `json:"user_id"`
+		Tags   *[]string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("SetTags: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.UserID == "" || payload.Tags == nil {
+		badRequest(w, "user_id and tags required")
+		return
+	}
+	tags, ok := domain.NormalizeTags(*payload.Tags)
+	if !ok {
+		badRequest(w, "tags must not be empty")
+		return
+	}
+	user, err := h.Repo.SetUserTags(r.Context(), payload.UserID, tags)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("SetTags: failed to set user tags: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{"user": buildAPIUser(user)})
 }
 
+type absencePayload struct {
+	AbsenceID int       `json:"absence_id"`
+	UserID    string    `json:"user_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    string    `json:"reason"`
+	// переназначить открытые ревью: сразу, если отсутствие уже идёт, иначе - когда начнётся
+	ReassignOpenReviews bool `json:"reassign_open_reviews"`
+}
+
+func (p absencePayload) validatePeriod() string {
+	if p.StartsAt.IsZero() || p.EndsAt.IsZero() {
+		return "starts_at and ends_at required"
+	}
+	if !p.EndsAt.After(p.StartsAt) {
+		return "ends_at must be after starts_at"
+	}
+	return ""
+}
+
+func (h *Handlers) AddAbsence(w http.ResponseWriter, r *http.Request) {
+	var payload absencePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("AddAbsence: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.UserID == "" {
+		badRequest(w, "user_id required")
+		return
+	}
+	if msg := payload.validatePeriod(); msg != "" {
+		badRequest(w, msg)
+		return
+	}
+	if _, err := h.Repo.GetUserByID(r.Context(), payload.UserID); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("AddAbsence: failed to get user: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	absence, err := h.Repo.AddAbsence(r.Context(), domain.Absence{
+		UserID:   payload.UserID,
+		StartsAt: payload.StartsAt.UTC(),
+		EndsAt:   payload.EndsAt.UTC(),
+		Reason:   payload.Reason,
+
+		ReassignOpenReviews: payload.ReassignOpenReviews,
+	})
+	if err != nil {
+		h.Log.Errorf("AddAbsence: failed to add absence: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	resp := map[string]interface{}{"absence": absence}
+	report, ok := h.reassignIfStarted(w, r, "AddAbsence", &absence)
+	if !ok {
+		return
+	}
+	if report != nil {
+		resp["reassignment"] = report
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// reassignIfStarted сразу переназначает открытые ревью, если отсутствие с флагом
+// reassign_open_reviews уже идёт; запланированное отсутствие обработает фоновый воркер
+// эскалации, когда оно начнётся. false - ответ с ошибкой уже записан.
+func (h *Handlers) reassignIfStarted(w http.ResponseWriter, r *http.Request, op string, absence *domain.Absence) (*domain.ReassignReport, bool) {
+	now := time.Now().UTC()
+	if !absence.ReassignOpenReviews || absence.ReassignedAt != nil || !absence.Covers(now) {
+		return nil, true
+	}
+	report, err := h.UC.ReassignOpenReviews(r.Context(), absence.UserID)
+	if err != nil {
+		h.Log.Errorf("%s: failed to reassign open reviews: %v", op, err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return nil, false
+	}
+	if err := h.Repo.MarkAbsenceReassigned(r.Context(), absence.ID, now); err != nil {
+		h.Log.Errorf("%s: failed to mark absence reassigned: %v", op, err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return nil, false
+	}
+	absence.ReassignedAt = &now
+	return &report, true
+}
+
+func (h *Handlers) GetAbsences(w http.ResponseWriter, r *http.Request) {
+	uid := r.URL.Query().Get("user_id")
+	if uid == "" {
+		badRequest(w, "user_id required")
+		return
+	}
+	if _, err := h.Repo.GetUserByID(r.Context(), uid); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("GetAbsences: failed to get user: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	absences, err := h.Repo.GetUserAbsences(r.Context(), uid)
+	if err != nil {
+		h.Log.Errorf("GetAbsences: failed to get absences: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	if absences == nil {
+		absences = []domain.Absence{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": uid, "absences": absences})
+}
+
+func (h *Handlers) UpdateAbsence(w http.ResponseWriter, r *http.Request) {
+	var payload absencePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("UpdateAbsence: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.AbsenceID == 0 {
+		badRequest(w, "absence_id required")
+		return
+	}
+	if msg := payload.validatePeriod(); msg != "" {
+		badRequest(w, msg)
+		return
+	}
+	absence, err := h.Repo.UpdateAbsence(r.Context(), domain.Absence{
+		ID:       payload.AbsenceID,
+		StartsAt: payload.StartsAt.UTC(),
+		EndsAt:   payload.EndsAt.UTC(),
+		Reason:   payload.Reason,
+
+		ReassignOpenReviews: payload.ReassignOpenReviews,
+	})
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "absence not found")
+			return
+		}
+		h.Log.Errorf("UpdateAbsence: failed to update absence: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	resp := map[string]interface{}{"absence": absence}
+	report, ok := h.reassignIfStarted(w, r, "UpdateAbsence", &absence)
+	if !ok {
+		return
+	}
+	if report != nil {
+		resp["reassignment"] = report
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handlers) DeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		AbsenceID int `json:"absence_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("DeleteAbsence: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.AbsenceID == 0 {
+		badRequest(w, "absence_id required")
+		return
+	}
+	if err := h.Repo.DeleteAbsence(r.Context(), payload.AbsenceID); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "absence not found")
+			return
+		}
+		h.Log.Errorf("DeleteAbsence: failed to delete absence: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"absence_id": payload.AbsenceID})
+}
+
 func (h *Handlers) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 	uid := r.URL.Query().Get("user_id")
 	if uid == "" {
@@ -199,21 +846,30 @@ func (h *Handlers) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 	}
 	short := make([]apiPullRequestShort, 0, len(prs))
 	for _, pr := range prs {
-		short = append(short, apiPullRequestShort{
+		item := apiPullRequestShort{
 			ID:       pr.ID,
 			Name:     pr.Title,
 			AuthorID: pr.AuthorID,
-			Status:   pr.Status,
-		})
+			Status:   string(pr.Status),
+		}
+		if review, ok := pr.ReviewDecisions[uid]; ok {
+			decidedAt := review.CreatedAt
+			item.Decision = string(review.Decision)
+			item.DecidedAt = &decidedAt
+		}
+		short = append(short, item)
 	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{"user_id": uid, "pull_requests": short})
 }
 
 func (h *Handlers) CreatePR(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		PullRequestID   string `json:"pull_request_id"`
-		PullRequestName string `json:"pull_request_name"`
-		AuthorID        string `json:"author_id"`
+		PullRequestID   string   `json:"pull_request_id"`
+		PullRequestName string   `json:"pull_request_name"`
+		AuthorID        string   `json:"author_id"`
+		Tags            []string `json:"tags"`
+		Files           []string `json:"files"`
+		Draft           bool     `json:"draft"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		h.Log.Errorf("CreatePR: failed to decode request body: %v", err)
@@ -224,10 +880,26 @@ func (h *Handlers) CreatePR(w http.ResponseWriter, r *http.Request) {
 		badRequest(w, "pull_request_id, pull_request_name and author_id required")
 		return
 	}
+	tags, ok := domain.NormalizeTags(payload.Tags)
+	if !ok {
+		badRequest(w, "tags must not be empty")
+		return
+	}
+	for _, f := range payload.Files {
+		if f == "" {
+			badRequest(w, "files must not contain empty paths")
+			return
+		}
+	}
 	pr := domain.PullRequest{
-		ID:       payload.PullRequestID,
-		Title:    payload.PullRequestName,
-		AuthorID: payload.AuthorID,
+		ID:           payload.PullRequestID,
+		Title:        payload.PullRequestName,
+		AuthorID:     payload.AuthorID,
+		Tags:         tags,
+		ChangedFiles: payload.Files,
+	}
+	if payload.Draft {
+		pr.Status = domain.StatusDraft
 	}
 	created, err := h.UC.CreatePR(r.Context(), pr)
 	if err != nil {
@@ -236,6 +908,8 @@ func (h *Handlers) CreatePR(w http.ResponseWriter, r *http.Request) {
 			errorResp(w, http.StatusConflict, codePRExists, "PR id already exists")
 		case uc.ErrNotFound:
 			notFound(w, "author or team not found")
+		case uc.ErrNotEnoughReviewers:
+			errorResp(w, http.StatusConflict, codeNotEnoughReviewers, "not enough available reviewers in team")
 		default:
 			h.Log.Errorf("CreatePR: failed to create PR: %v", err)
 			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
@@ -266,6 +940,8 @@ func (h *Handlers) Reassign(w http.ResponseWriter, r *http.Request) {
 			notFound(w, "PR not found")
 		case uc.ErrPRMerged:
 			errorResp(w, http.StatusConflict, codePRMerged, "cannot reassign on merged PR")
+		case uc.ErrPRClosed:
+			errorResp(w, http.StatusConflict, codePRClosed, "cannot reassign on closed PR")
 		case uc.ErrNotAssigned:
 			errorResp(w, http.StatusConflict, codeNotAssigned, "reviewer is not assigned to this PR")
 		case uc.ErrNoCandidate:
@@ -285,9 +961,54 @@ func (h *Handlers) Reassign(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr, "replaced_by": newID})
 }
 
+func (h *Handlers) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		PullRequestID string `json:"pull_request_id"`
+		ReviewerID    string `json:"reviewer_id"`
+		Decision      string `json:"decision"`
+		Comment       string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("SubmitReview: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.PullRequestID == "" || payload.ReviewerID == "" || payload.Decision == "" {
+		badRequest(w, "pull_request_id, reviewer_id and decision required")
+		return
+	}
+	review, err := h.UC.SubmitReview(r.Context(), domain.Review{
+		PullRequestID: payload.PullRequestID,
+		ReviewerID:    payload.ReviewerID,
+		Decision:      domain.ReviewDecision(payload.Decision),
+		Comment:       payload.Comment,
+	})
+	if err != nil {
+		switch err {
+		case uc.ErrValidation:
+			badRequest(w, "decision must be one of APPROVED, CHANGES_REQUESTED, COMMENTED")
+		case uc.ErrNotFound:
+			notFound(w, "PR not found")
+		case uc.ErrPRMerged:
+			errorResp(w, http.StatusConflict, codePRMerged, "cannot review merged PR")
+		case uc.ErrPRClosed:
+			errorResp(w, http.StatusConflict, codePRClosed, "cannot review closed PR")
+		case uc.ErrNotAssigned:
+			errorResp(w, http.StatusConflict, codeNotAssigned, "reviewer is not assigned to this PR")
+		default:
+			h.Log.Errorf("SubmitReview: failed to submit review: %v", err)
+			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"review": review})
+}
+
 func (h *Handlers) Merge(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
 		PullRequestID string `json:"pull_request_id"`
+		Force         bool   `json:"force"`
+		ForcedBy      string `json:"forced_by"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		h.Log.Errorf("Merge: failed to decode request body: %v", err)
@@ -298,14 +1019,106 @@ func (h *Handlers) Merge(w http.ResponseWriter, r *http.Request) {
 		badRequest(w, "pull_request_id required")
 		return
 	}
-	pr, err := h.UC.MergePR(r.Context(), payload.PullRequestID)
+	var pr domain.PullRequest
+	var err error
+	if payload.Force {
+		if payload.ForcedBy == "" {
+			badRequest(w, "forced_by required for force merge")
+			return
+		}
+		if !h.isAdmin(r) {
+			errorResp(w, http.StatusForbidden, codeForbidden, "force merge requires admin token")
+			return
+		}
+		// в PR и в журнал попадает только существующий пользователь
+		if _, err := h.Repo.GetUserByID(r.Context(), payload.ForcedBy); err != nil {
+			if err == repository.ErrNotFound {
+				notFound(w, "forced_by user not found")
+				return
+			}
+			h.Log.Errorf("Merge: failed to get forced_by user: %v", err)
+			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+			return
+		}
+		pr, err = h.UC.ForceMergePR(r.Context(), payload.PullRequestID, payload.ForcedBy)
+		if err == nil {
+			h.Log.Infof("Merge: PR %s force merged by %s", payload.PullRequestID, payload.ForcedBy)
+		}
+	} else {
+		pr, err = h.UC.MergePR(r.Context(), payload.PullRequestID)
+	}
 	if err != nil {
-		if err == uc.ErrNotFound {
+		var blocked *uc.MergeBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			writeJSON(w, http.StatusConflict, map[string]interface{}{
+				"error": map[string]interface{}{
+					"code":    codeMergeBlocked,
+					"message": "merge policy is not satisfied",
+					"unmet":   blocked.Unmet,
+				},
+			})
+		case err == uc.ErrNotFound:
 			notFound(w, "PR not found")
-			return
+		case err == uc.ErrInvalidTransition:
+			errorResp(w, http.StatusConflict, codeInvalidTransition, "status transition is not allowed")
+		default:
+			h.Log.Errorf("Merge: failed to merge PR: %v", err)
+			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+// isAdmin проверяет токен администратора в заголовке запроса.
+func (h *Handlers) isAdmin(r *http.Request) bool {
+	token := r.Header.Get(adminTokenHeader)
+	return h.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) == 1
+}
+
+func (h *Handlers) Close(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "Close", h.UC.ClosePR)
+}
+
+func (h *Handlers) Reopen(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "Reopen", h.UC.ReopenPR)
+}
+
+func (h *Handlers) MarkDraft(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "MarkDraft", h.UC.MarkDraft)
+}
+
+func (h *Handlers) MarkReady(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, "MarkReady", h.UC.MarkReady)
+}
+
+// changeStatus обрабатывает запросы смены статуса PR вида {"pull_request_id": ...}
+func (h *Handlers) changeStatus(w http.ResponseWriter, r *http.Request, op string,
+	fn func(ctx context.Context, prID string) (domain.PullRequest, error)) {
+	var payload struct {
+		PullRequestID string `json:"pull_request_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("%s: failed to decode request body: %v", op, err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.PullRequestID == "" {
+		badRequest(w, "pull_request_id required")
+		return
+	}
+	pr, err := fn(r.Context(), payload.PullRequestID)
+	if err != nil {
+		switch err {
+		case uc.ErrNotFound:
+			notFound(w, "PR not found")
+		case uc.ErrInvalidTransition:
+			errorResp(w, http.StatusConflict, codeInvalidTransition, "status transition is not allowed")
+		default:
+			h.Log.Errorf("%s: failed to change PR status: %v", op, err)
+			errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
 		}
-		h.Log.Errorf("Merge: failed to merge PR: %v", err)
-		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
@@ -320,35 +1133,172 @@ func (h *Handlers) GetStats(w http.ResponseWriter, r *http.Request) {
 	}
 	apiStats := make([]map[string]interface{}, 0, len(stats))
 	for _, stat := range stats {
-		apiStats = append(apiStats, map[string]interface{}{
-			"user_id":           stat.UserID,
-			"username":          stat.Username,
-			"assignments_count": stat.Count,
-		})
+		item := map[string]interface{}{
+			"user_id":                          stat.UserID,
+			"username":                         stat.Username,
+			"assignments_count":                stat.Count,
+			"reviewed_count":                   stat.ReviewedCount,
+			"avg_time_to_first_review_seconds": nil,
+		}
+		// без решений среднее не определено и отдаётся null
+		if stat.ReviewedCount > 0 {
+			item["avg_time_to_first_review_seconds"] = int64(stat.AvgTimeToFirstReview / time.Second)
+		}
+		apiStats = append(apiStats, item)
 	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{"statistics": apiStats})
 }
 
+func (h *Handlers) GetOverdue(w http.ResponseWriter, r *http.Request) {
+	prs, err := h.Repo.GetOverduePRs(r.Context(), time.Now().UTC())
+	if err != nil {
+		h.Log.Errorf("GetOverdue: failed to get overdue PRs: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pull_requests": prs})
+}
+
+func (h *Handlers) GetPR(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	prID := q.Get("pull_request_id")
+	if prID == "" {
+		badRequest(w, "pull_request_id required")
+		return
+	}
+	var pr domain.PullRequest
+	var err error
+	if v := q.Get("at"); v != "" {
+		at, perr := time.Parse(time.RFC3339, v)
+		if perr != nil {
+			badRequest(w, "at must be an RFC3339 timestamp")
+			return
+		}
+		pr, err = h.Repo.GetPRAt(r.Context(), prID, at)
+	} else {
+		pr, err = h.Repo.GetPR(r.Context(), prID)
+	}
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "PR not found")
+			return
+		}
+		if err == repository.ErrHistoryUnavailable {
+			errorResp(w, http.StatusUnprocessableEntity, codeHistoryUnavailable, "PR history is not available for this time")
+			return
+		}
+		h.Log.Errorf("GetPR: failed to get PR: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pr": pr})
+}
+
+func (h *Handlers) GetPRHistory(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		badRequest(w, "pull_request_id required")
+		return
+	}
+	events, err := h.Repo.GetPRHistory(r.Context(), prID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "PR not found")
+			return
+		}
+		h.Log.Errorf("GetPRHistory: failed to get history: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"pull_request_id": prID, "events": events})
+}
+
 func buildAPITeam(team domain.Team, members []domain.User) apiTeam {
+	minReviewers, maxReviewers := team.ReviewerLimits()
 	resp := apiTeam{
-		TeamName: team.Name,
-		Members:  make([]apiTeamMember, 0, len(members)),
+		TeamName:         team.Name,
+		ReviewerStrategy: team.ReviewerStrategy,
+		MinReviewers:     minReviewers,
+		MaxReviewers:     maxReviewers,
+		FallbackTeams:    append([]string{}, team.FallbackTeams...),
+		Members:          make([]apiTeamMember, 0, len(members)),
+
+		RequiredApprovals:     team.RequiredApprovals,
+		AllowChangesRequested: team.AllowChangesRequested,
+		ReviewSLAHours:        team.ReviewSLAHours,
+
+		EscalationHours:  team.EscalationHours,
+		EscalationAction: string(team.EscalationAction),
+
+		ChatWebhookConfigured: team.ChatWebhookURL != "",
+		ChatChannel:           team.ChatChannel,
 	}
 	for _, m := range members {
 		resp.Members = append(resp.Members, apiTeamMember{
-			UserID:   m.ID,
-			Username: m.Username,
-			IsActive: m.IsActive,
+			UserID:               m.ID,
+			Username:             m.Username,
+			IsActive:             m.IsActive,
+			MaxConcurrentReviews: m.MaxConcurrentReviews,
+			OpenReviews:          m.OpenReviews,
+			Tags:                 append([]string{}, m.Tags...),
+			ChatHandle:           m.ChatHandle,
+			Email:                m.Email,
+			DigestOptOut:         m.DigestOptOut,
 		})
 	}
 	return resp
 }
 
+// validateTeamSettings возвращает текст ошибки валидации или пустую строку.
+func validateTeamSettings(t domain.Team) string {
+	if t.ReviewerStrategy != "" && !domain.IsValidReviewerStrategy(t.ReviewerStrategy) {
+		return "unknown reviewer_strategy"
+	}
+	if t.MinReviewers < 0 || t.MaxReviewers < 0 {
+		return "min_reviewers and max_reviewers must not be negative"
+	}
+	if minReviewers, maxReviewers := t.ReviewerLimits(); minReviewers > maxReviewers {
+		return "min_reviewers must not exceed max_reviewers"
+	}
+	if t.RequiredApprovals < 0 {
+		return "required_approvals must not be negative"
+	}
+	if t.ReviewSLAHours < 0 {
+		return "review_sla_hours must not be negative"
+	}
+	if t.EscalationHours < 0 {
+		return "escalation_hours must not be negative"
+	}
+	if t.EscalationAction != "" && !domain.IsValidEscalationAction(t.EscalationAction) {
+		return "unknown escalation_action"
+	}
+	if t.ChatWebhookURL != "" && !isHTTPURL(t.ChatWebhookURL) {
+		return "chat_webhook_url must be an absolute http(s) URL"
+	}
+	seen := map[string]struct{}{}
+	for _, name := range t.FallbackTeams {
+		if name == "" || name == t.Name {
+			return "fallback_teams must not contain empty names or the team itself"
+		}
+		if _, dup := seen[name]; dup {
+			return "fallback_teams must not contain duplicates"
+		}
+		seen[name] = struct{}{}
+	}
+	return ""
+}
+
 func buildAPIUser(u domain.User) apiUser {
 	return apiUser{
-		UserID:   u.ID,
-		Username: u.Username,
-		TeamName: u.TeamName,
-		IsActive: u.IsActive,
+		UserID:               u.ID,
+		Username:             u.Username,
+		TeamName:             u.TeamName,
+		IsActive:             u.IsActive,
+		MaxConcurrentReviews: u.MaxConcurrentReviews,
+		OpenReviews:          u.OpenReviews,
+		Tags:                 append([]string{}, u.Tags...),
+		ChatHandle:           u.ChatHandle,
+		Email:                u.Email,
+		DigestOptOut:         u.DigestOptOut,
 	}
 }
@@ -6,12 +6,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/you/pr-assign-avito/internal/domain"
 	"github.com/you/pr-assign-avito/internal/infra"
 	"github.com/you/pr-assign-avito/internal/repository"
 	uc "github.com/you/pr-assign-avito/internal/usecase"
+	"github.com/you/pr-assign-avito/internal/webhook"
 )
 
 // Mock repository для тестов handlers
@@ -21,6 +24,15 @@ type mockRepo struct {
 	prs       map[string]domain.PullRequest
 	reviewers map[string][]string
 	stats     []repository.ReviewerStat
+	overdue   []domain.OverduePR
+	webhooks  []domain.Webhook
+	absences  map[int]domain.Absence
+	reviews   []domain.Review
+	history   []domain.AssignmentEvent
+	// с какого момента известна история PR; нет записи - с создания
+	historyFrom map[string]time.Time
+
+	accounts map[string]string
 }
 
 func newMockRepo() *mockRepo {
@@ -30,15 +42,20 @@ func newMockRepo() *mockRepo {
 		prs:       make(map[string]domain.PullRequest),
 		reviewers: make(map[string][]string),
 		stats:     make([]repository.ReviewerStat, 0),
+		absences:  make(map[int]domain.Absence),
 	}
 }
 
-func (m *mockRepo) CreateTeamWithMembers(ctx context.Context, teamName string, members []domain.User) error {
-	if _, exists := m.teams[teamName]; exists {
+func (m *mockRepo) CreateTeamWithMembers(ctx context.Context, team domain.Team, members []domain.TeamMember) error {
+	if _, exists := m.teams[team.Name]; exists {
 		return repository.ErrTeamExists
 	}
-	m.teams[teamName] = domain.Team{ID: len(m.teams) + 1, Name: teamName}
-	for _, u := range members {
+	team.ID = len(m.teams) + 1
+	m.teams[team.Name] = team
+	for _, tm := range members {
+		u := tm.ApplyTo(m.users[tm.ID])
+		u.TeamID = team.ID
+		u.TeamName = team.Name
 		m.users[u.ID] = u
 	}
 	return nil
@@ -58,6 +75,36 @@ func (m *mockRepo) GetTeamByName(ctx context.Context, name string) (domain.Team,
 	return team, members, nil
 }
 
+func (m *mockRepo) GetTeamByID(ctx context.Context, teamID int) (domain.Team, error) {
+	for _, t := range m.teams {
+		if t.ID == teamID {
+			return t, nil
+		}
+	}
+	// пользователи в тестах часто заводятся напрямую, без команды
+	return domain.Team{ID: teamID}, nil
+}
+
+func (m *mockRepo) UpdateTeam(ctx context.Context, team domain.Team) error {
+	stored, ok := m.teams[team.Name]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	team.ID = stored.ID
+	m.teams[team.Name] = team
+	return nil
+}
+
+func (m *mockRepo) SetTeamCodeowners(ctx context.Context, teamName, doc string) error {
+	team, ok := m.teams[teamName]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	team.Codeowners = doc
+	m.teams[teamName] = team
+	return nil
+}
+
 func (m *mockRepo) SetUserActive(ctx context.Context, userID string, active bool) (domain.User, error) {
 	u, ok := m.users[userID]
 	if !ok {
@@ -68,6 +115,87 @@ func (m *mockRepo) SetUserActive(ctx context.Context, userID string, active bool
 	return u, nil
 }
 
+func (m *mockRepo) DeactivateUsers(ctx context.Context, userIDs []string, replacements []domain.Replacement) error {
+	for _, id := range userIDs {
+		u, ok := m.users[id]
+		if !ok {
+			return repository.ErrNotFound
+		}
+		u.IsActive = false
+		m.users[id] = u
+	}
+	for _, r := range replacements {
+		if err := m.ReplacePRReviewer(ctx, r.PullRequestID, r.OldReviewerID, r.NewReviewerID, domain.ReasonTeamDeactivation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockRepo) SetUserMaxReviews(ctx context.Context, userID string, maxReviews int) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.MaxConcurrentReviews = maxReviews
+	m.users[userID] = u
+	return u, nil
+}
+
+func (m *mockRepo) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.Tags = tags
+	m.users[userID] = u
+	return u, nil
+}
+
+func (m *mockRepo) SetUserChatHandle(ctx context.Context, userID, handle string) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.ChatHandle = handle
+	m.users[userID] = u
+	return u, nil
+}
+
+func (m *mockRepo) SetUserDigest(ctx context.Context, userID, email string, optOut bool) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.Email, u.DigestOptOut = email, optOut
+	m.users[userID] = u
+	return u, nil
+}
+
+func (m *mockRepo) GetPRHistory(ctx context.Context, prID string) ([]domain.AssignmentEvent, error) {
+	if _, ok := m.prs[prID]; !ok {
+		return nil, repository.ErrNotFound
+	}
+	events := []domain.AssignmentEvent{}
+	for _, e := range m.history {
+		if e.PullRequestID == prID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (m *mockRepo) GetPRAt(ctx context.Context, prID string, at time.Time) (domain.PullRequest, error) {
+	pr, ok := m.prs[prID]
+	if !ok || pr.CreatedAt.After(at) {
+		return domain.PullRequest{}, repository.ErrNotFound
+	}
+	if from, ok := m.historyFrom[prID]; ok && at.Before(from) {
+		return domain.PullRequest{}, repository.ErrHistoryUnavailable
+	}
+	return pr, nil
+}
+
 func (m *mockRepo) GetUserByID(ctx context.Context, userID string) (domain.User, error) {
 	u, ok := m.users[userID]
 	if !ok {
@@ -82,6 +210,9 @@ func (m *mockRepo) GetUserReviews(ctx context.Context, userID string) ([]domain.
 		for _, r := range reviewers {
 			if r == userID {
 				if pr, ok := m.prs[prID]; ok {
+					if review, ok := m.latestReview(prID, userID); ok {
+						pr.ReviewDecisions = map[string]domain.Review{userID: review}
+					}
 					prs = append(prs, pr)
 				}
 				break
@@ -97,11 +228,46 @@ func (m *mockRepo) CreatePR(ctx context.Context, pr domain.PullRequest, status s
 	return nil
 }
 
+func (m *mockRepo) MergePR(ctx context.Context, prID, forcedBy string) ([]string, error) {
+	pr, ok := m.prs[prID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if pr.Status == domain.StatusMerged {
+		return nil, nil
+	}
+	if !domain.CanTransition(pr.Status, domain.StatusMerged) {
+		return nil, repository.ErrInvalidTransition
+	}
+	if forcedBy == "" {
+		team, _ := m.GetTeamByID(ctx, m.users[pr.AuthorID].TeamID)
+		current, _ := m.GetPR(ctx, prID)
+		if unmet := team.MergeBlockers(current.ReviewDecisions); len(unmet) > 0 {
+			return unmet, nil
+		}
+	}
+	t := time.Now().UTC()
+	pr.Status = domain.StatusMerged
+	pr.MergedAt = &t
+	pr.MergeForcedBy = forcedBy
+	m.prs[prID] = pr
+	return nil, nil
+}
+
 func (m *mockRepo) GetPR(ctx context.Context, prID string) (domain.PullRequest, error) {
 	pr, ok := m.prs[prID]
 	if !ok {
 		return domain.PullRequest{}, repository.ErrNotFound
 	}
+	pr.ReviewDecisions = nil
+	for _, r := range m.reviewers[prID] {
+		if review, ok := m.latestReview(prID, r); ok {
+			if pr.ReviewDecisions == nil {
+				pr.ReviewDecisions = map[string]domain.Review{}
+			}
+			pr.ReviewDecisions[r] = review
+		}
+	}
 	return pr, nil
 }
 
@@ -121,11 +287,40 @@ func (m *mockRepo) GetActiveTeamMembersExcluding(ctx context.Context, teamID int
 	return users, nil
 }
 
+func (m *mockRepo) AddReview(ctx context.Context, r domain.Review) (domain.Review, error) {
+	pr, ok := m.prs[r.PullRequestID]
+	if !ok {
+		return domain.Review{}, repository.ErrNotFound
+	}
+	if pr.Status == domain.StatusMerged {
+		return domain.Review{}, repository.ErrPRMerged
+	}
+	if !pr.Status.CanChangeReviewers() {
+		return domain.Review{}, repository.ErrPRClosed
+	}
+	if assigned, _ := m.IsReviewerAssigned(ctx, r.PullRequestID, r.ReviewerID); !assigned {
+		return domain.Review{}, repository.ErrNotAssigned
+	}
+	r.ID = len(m.reviews) + 1
+	r.CreatedAt = time.Now().UTC()
+	m.reviews = append(m.reviews, r)
+	return r, nil
+}
+
+func (m *mockRepo) latestReview(prID, reviewerID string) (domain.Review, bool) {
+	for i := len(m.reviews) - 1; i >= 0; i-- {
+		if r := m.reviews[i]; r.PullRequestID == prID && r.ReviewerID == reviewerID {
+			return r, true
+		}
+	}
+	return domain.Review{}, false
+}
+
 func (m *mockRepo) GetPRReviewers(ctx context.Context, prID string) ([]string, error) {
 	return m.reviewers[prID], nil
 }
 
-func (m *mockRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
+func (m *mockRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string, reason domain.AssignmentReason) error {
 	reviewers, ok := m.reviewers[prID]
 	if !ok {
 		return repository.ErrNotFound
@@ -142,15 +337,26 @@ func (m *mockRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUs
 		return repository.ErrNotAssigned
 	}
 	m.reviewers[prID] = reviewers
+	m.history = append(m.history, domain.AssignmentEvent{
+		ID:            int64(len(m.history) + 1),
+		PullRequestID: prID,
+		Actor:         domain.ActorFrom(ctx),
+		OldReviewerID: oldUserID,
+		NewReviewerID: newUserID,
+		Reason:        reason,
+	})
 	return nil
 }
 
-func (m *mockRepo) MergePR(ctx context.Context, prID string) error {
+func (m *mockRepo) TransitionPR(ctx context.Context, prID string, to domain.PRStatus) error {
 	pr, ok := m.prs[prID]
 	if !ok {
 		return repository.ErrNotFound
 	}
-	pr.Status = "MERGED"
+	if !domain.CanTransition(pr.Status, to) {
+		return repository.ErrInvalidTransition
+	}
+	pr.Status = to
 	m.prs[prID] = pr
 	return nil
 }
@@ -192,10 +398,131 @@ func (m *mockRepo) HasOpenPRsAsReviewer(ctx context.Context, userID string) (boo
 	return false, nil
 }
 
+func (m *mockRepo) GetOverduePRs(ctx context.Context, now time.Time) ([]domain.OverduePR, error) {
+	return m.overdue, nil
+}
+
+func (m *mockRepo) GetStaleReviews(ctx context.Context, now time.Time) ([]domain.StaleReview, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) AddEscalationReviewer(ctx context.Context, prID, staleReviewerID, newReviewerID string) error {
+	m.reviewers[prID] = append(m.reviewers[prID], newReviewerID)
+	return nil
+}
+
+func (m *mockRepo) AddWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error) {
+	w.ID = len(m.webhooks) + 1
+	w.CreatedAt = time.Now().UTC()
+	m.webhooks = append(m.webhooks, w)
+	return w, nil
+}
+
+func (m *mockRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return m.webhooks, nil
+}
+
+func (m *mockRepo) DeleteWebhook(ctx context.Context, webhookID int) error {
+	for i, w := range m.webhooks {
+		if w.ID == webhookID {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (m *mockRepo) LinkExternalAccount(ctx context.Context, a domain.ExternalAccount) (domain.ExternalAccount, error) {
+	if m.accounts == nil {
+		m.accounts = make(map[string]string)
+	}
+	m.accounts[a.Provider+"/"+a.Login] = a.UserID
+	return a, nil
+}
+
+func (m *mockRepo) UnlinkExternalAccount(ctx context.Context, provider, login string) error {
+	if _, ok := m.accounts[provider+"/"+login]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.accounts, provider+"/"+login)
+	return nil
+}
+
+func (m *mockRepo) GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error) {
+	id, ok := m.accounts[provider+"/"+login]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	return id, nil
+}
+
+func (m *mockRepo) GetExternalLogin(ctx context.Context, provider, userID string) (string, error) {
+	for key, id := range m.accounts {
+		if id == userID && strings.HasPrefix(key, provider+"/") {
+			return strings.TrimPrefix(key, provider+"/"), nil
+		}
+	}
+	return "", repository.ErrNotFound
+}
+
 func (m *mockRepo) GetReviewerStats(ctx context.Context) ([]repository.ReviewerStat, error) {
 	return m.stats, nil
 }
 
+func (m *mockRepo) AddAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	a.ID = len(m.absences) + 1
+	m.absences[a.ID] = a
+	return a, nil
+}
+
+func (m *mockRepo) GetUserAbsences(ctx context.Context, userID string) ([]domain.Absence, error) {
+	var res []domain.Absence
+	for _, a := range m.absences {
+		if a.UserID == userID {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) UpdateAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	stored, ok := m.absences[a.ID]
+	if !ok {
+		return domain.Absence{}, repository.ErrNotFound
+	}
+	a.UserID = stored.UserID
+	if a.StartsAt.Equal(stored.StartsAt) {
+		a.ReassignedAt = stored.ReassignedAt
+	}
+	m.absences[a.ID] = a
+	return a, nil
+}
+
+func (m *mockRepo) DeleteAbsence(ctx context.Context, absenceID int) error {
+	if _, ok := m.absences[absenceID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.absences, absenceID)
+	return nil
+}
+
+func (m *mockRepo) DueAbsenceReassignments(ctx context.Context, now time.Time) ([]domain.Absence, error) {
+	var res []domain.Absence
+	for _, a := range m.absences {
+		if a.ReassignOpenReviews && a.ReassignedAt == nil && a.Covers(now) {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockRepo) MarkAbsenceReassigned(ctx context.Context, absenceID int, at time.Time) error {
+	a := m.absences[absenceID]
+	a.ReassignedAt = &at
+	m.absences[absenceID] = a
+	return nil
+}
+
 func TestHealth(t *testing.T) {
 	repo := newMockRepo()
 	ucase := uc.NewPRUsecase(repo)
@@ -254,6 +581,32 @@ func TestAddTeam_Success(t *testing.T) {
 	}
 }
 
+func TestAddTeam_KeepsMemberSettings(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{
+		ID: "u1", Username: "alice", TeamName: "old", IsActive: true, MaxConcurrentReviews: 3,
+		Tags: []string{"go"}, ChatHandle: "<@U1>", Email: "alice@example.com", DigestOptOut: true,
+	}
+	ucase := uc.NewPRUsecase(repo)
+	handlers := NewHandlers(ucase, repo, infra.NewStdLogger())
+
+	body := `{"team_name":"backend","members":[{"user_id":"u1","username":"alice","is_active":true,"email":"a@example.com"}]}`
+	req := httptest.NewRequest("POST", "/team/add", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.AddTeam(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	u := repo.users["u1"]
+	if u.TeamName != "backend" || u.Email != "a@example.com" {
+		t.Fatalf("expected team and email from request, got %+v", u)
+	}
+	if u.MaxConcurrentReviews != 3 || len(u.Tags) != 1 || u.ChatHandle != "<@U1>" || !u.DigestOptOut {
+		t.Fatalf("expected unsent settings kept, got %+v", u)
+	}
+}
+
 func TestAddTeam_InvalidJSON(t *testing.T) {
 	repo := newMockRepo()
 	ucase := uc.NewPRUsecase(repo)
@@ -360,176 +713,693 @@ func TestGetTeam_NotFound(t *testing.T) {
 	}
 }
 
-func TestSetIsActive_Success(t *testing.T) {
+func TestUpdateTeam_Success(t *testing.T) {
 	repo := newMockRepo()
-	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
 	payload := map[string]interface{}{
-		"user_id":   "u1",
-		"is_active": false,
+		"team_name":     "backend",
+		"min_reviewers": 1,
+		"max_reviewers": 3,
 	}
 	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/users/setIsActive", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/team/update", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.SetIsActive(w, req)
+	handlers.UpdateTeam(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d", w.Code)
 	}
+	if got := repo.teams["backend"]; got.MinReviewers != 1 || got.MaxReviewers != 3 {
+		t.Fatalf("expected limits 1..3, got %d..%d", got.MinReviewers, got.MaxReviewers)
+	}
 }
 
-func TestSetIsActive_UserNotFound(t *testing.T) {
+func TestUpdateTeam_InvalidLimits(t *testing.T) {
 	repo := newMockRepo()
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
 	payload := map[string]interface{}{
-		"user_id":   "nonexistent",
-		"is_active": false,
+		"team_name":     "backend",
+		"min_reviewers": 3,
+		"max_reviewers": 2,
 	}
 	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/users/setIsActive", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/team/update", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.SetIsActive(w, req)
+	handlers.UpdateTeam(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Fatalf("expected status 404, got %d", w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 }
 
-func TestCreatePR_Success(t *testing.T) {
+func TestUpdateTeam_SelfFallback(t *testing.T) {
 	repo := newMockRepo()
-	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
-	repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
-	payload := map[string]interface{}{
-		"pull_request_id":   "pr1",
-		"pull_request_name": "feat",
-		"author_id":         "u1",
-	}
-	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/pullRequest/create", bytes.NewReader(body))
+	body, _ := json.Marshal(map[string]interface{}{"team_name": "backend", "fallback_teams": []string{"backend"}})
+	req := httptest.NewRequest("POST", "/team/update", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.CreatePR(w, req)
+	handlers.UpdateTeam(w, req)
 
-	if w.Code != http.StatusCreated {
-		t.Fatalf("expected status 201, got %d", w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 }
 
-func TestCreatePR_MissingFields(t *testing.T) {
+func TestUpdateTeam_ChatSettings(t *testing.T) {
 	repo := newMockRepo()
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
 	payload := map[string]interface{}{
-		"pull_request_id": "pr1",
+		"team_name":        "backend",
+		"chat_webhook_url": "https://hooks.slack.com/services/T000/B000/XXXX",
+		"chat_channel":     "#backend-reviews",
 	}
 	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/pullRequest/create", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/team/update", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.CreatePR(w, req)
+	handlers.UpdateTeam(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := repo.teams["backend"]; got.ChatWebhookURL != payload["chat_webhook_url"] || got.ChatChannel != "#backend-reviews" {
+		t.Fatalf("expected chat settings saved, got %+v", got)
+	}
+	// URL вебхука даёт право писать в канал и в ответ не попадает
+	if bytes.Contains(w.Body.Bytes(), []byte("hooks.slack.com")) || !bytes.Contains(w.Body.Bytes(), []byte(`"chat_webhook_configured":true`)) {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{"team_name": "backend", "chat_webhook_url": "hooks.slack.com/services/T000"})
+	req = httptest.NewRequest("POST", "/team/update", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	handlers.UpdateTeam(w, req)
 
 	if w.Code != http.StatusBadRequest {
-		t.Fatalf("expected status 400, got %d", w.Code)
+		t.Fatalf("expected status 400 for relative url, got %d", w.Code)
 	}
 }
 
-func TestCreatePR_AuthorNotFound(t *testing.T) {
+func TestUpdateTeam_NotFound(t *testing.T) {
 	repo := newMockRepo()
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
-	payload := map[string]interface{}{
-		"pull_request_id":   "pr1",
-		"pull_request_name": "feat",
-		"author_id":         "nonexistent",
-	}
-	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/pullRequest/create", bytes.NewReader(body))
+	body, _ := json.Marshal(map[string]interface{}{"team_name": "ghost", "max_reviewers": 1})
+	req := httptest.NewRequest("POST", "/team/update", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.CreatePR(w, req)
+	handlers.UpdateTeam(w, req)
 
 	if w.Code != http.StatusNotFound {
 		t.Fatalf("expected status 404, got %d", w.Code)
 	}
 }
 
-func TestReassign_Success(t *testing.T) {
+func TestSetCodeowners_Success(t *testing.T) {
 	repo := newMockRepo()
-	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
-	repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
-	repo.users["u3"] = domain.User{ID: "u3", Username: "carl", TeamID: 1, IsActive: true}
-	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "fix", AuthorID: "u1", Status: "OPEN"}
-	repo.reviewers["pr1"] = []string{"u2"}
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
-	payload := map[string]interface{}{
-		"pull_request_id": "pr1",
-		"old_user_id":     "u2",
-	}
-	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/pullRequest/reassign", bytes.NewReader(body))
+	body, _ := json.Marshal(map[string]interface{}{"team_name": "backend", "codeowners": "*.sql @u4\n/docs/ @org/docs\n"})
+	req := httptest.NewRequest("POST", "/team/setCodeowners", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.Reassign(w, req)
+	handlers.SetCodeowners(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d", w.Code)
 	}
+	if repo.teams["backend"].Codeowners == "" {
+		t.Fatalf("expected codeowners to be stored")
+	}
 }
 
-func TestReassign_PRNotFound(t *testing.T) {
+func TestSetCodeowners_Invalid(t *testing.T) {
 	repo := newMockRepo()
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
-	payload := map[string]interface{}{
-		"pull_request_id": "nonexistent",
-		"old_user_id":     "u1",
-	}
-	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/pullRequest/reassign", bytes.NewReader(body))
+	body, _ := json.Marshal(map[string]interface{}{"team_name": "backend", "codeowners": "docs/ not-an-owner"})
+	req := httptest.NewRequest("POST", "/team/setCodeowners", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.Reassign(w, req)
+	handlers.SetCodeowners(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Fatalf("expected status 404, got %d", w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 }
 
-func TestMerge_Success(t *testing.T) {
+func TestDeactivateTeamUsers_Success(t *testing.T) {
 	repo := newMockRepo()
-	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
-	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "merge", AuthorID: "u1", Status: "OPEN"}
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
+	for _, u := range []domain.User{
+		{ID: "u1", Username: "alice", TeamID: 1, TeamName: "backend", IsActive: true},
+		{ID: "u2", Username: "bob", TeamID: 1, TeamName: "backend", IsActive: true},
+		{ID: "u3", Username: "carl", TeamID: 1, TeamName: "backend", IsActive: true},
+	} {
+		repo.users[u.ID] = u
+	}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: "OPEN"}
+	repo.reviewers["pr1"] = []string{"u2"}
 	ucase := uc.NewPRUsecase(repo)
 	logger := infra.NewStdLogger()
 	handlers := NewHandlers(ucase, repo, logger)
 
 	payload := map[string]interface{}{
-		"pull_request_id": "pr1",
+		"team_name": "backend",
+		"user_ids":  []string{"u2"},
 	}
 	body, _ := json.Marshal(payload)
-	req := httptest.NewRequest("POST", "/pullRequest/merge", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/team/deactivateUsers", bytes.NewReader(body))
 	w := httptest.NewRecorder()
-	handlers.Merge(w, req)
+	handlers.DeactivateTeamUsers(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d", w.Code)
 	}
-}
-
+	var response struct {
+		Result domain.TeamDeactivation `json:"result"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Result.Reassigned) != 1 || response.Result.Reassigned[0].NewReviewerID != "u3" {
+		t.Fatalf("expected pr1 reassigned to u3, got %+v", response.Result)
+	}
+	if repo.users["u2"].IsActive {
+		t.Fatalf("expected u2 to be deactivated")
+	}
+}
+
+func TestDeactivateTeamUsers_MissingUsers(t *testing.T) {
+	repo := newMockRepo()
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"team_name": "backend"})
+	req := httptest.NewRequest("POST", "/team/deactivateUsers", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.DeactivateTeamUsers(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestSetIsActive_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"user_id":   "u1",
+		"is_active": false,
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/users/setIsActive", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetIsActive(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+}
+
+func TestSetIsActive_ReassignOpenReviews(t *testing.T) {
+	repo := newMockRepo()
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend"}
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+	repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+	repo.users["u3"] = domain.User{ID: "u3", Username: "carl", TeamID: 1, IsActive: true}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: "OPEN"}
+	repo.reviewers["pr1"] = []string{"u2"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"user_id":               "u2",
+		"is_active":             false,
+		"reassign_open_reviews": true,
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/users/setIsActive", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetIsActive(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Reassignment domain.ReassignReport `json:"reassignment"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Reassignment.Reassigned) != 1 || response.Reassignment.Reassigned[0].NewReviewerID != "u3" {
+		t.Fatalf("expected pr1 reassigned to u3, got %+v", response.Reassignment)
+	}
+	if repo.reviewers["pr1"][0] != "u3" {
+		t.Fatalf("expected u3 to be assigned, got %v", repo.reviewers["pr1"])
+	}
+}
+
+func TestSetIsActive_UserNotFound(t *testing.T) {
+	repo := newMockRepo()
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"user_id":   "nonexistent",
+		"is_active": false,
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/users/setIsActive", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetIsActive(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestSetMaxReviews_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"user_id": "u1", "max_concurrent_reviews": 3})
+	req := httptest.NewRequest("POST", "/users/setMaxReviews", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetMaxReviews(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if repo.users["u1"].MaxConcurrentReviews != 3 {
+		t.Fatalf("expected max_concurrent_reviews 3, got %d", repo.users["u1"].MaxConcurrentReviews)
+	}
+}
+
+func TestSetMaxReviews_Negative(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"user_id": "u1", "max_concurrent_reviews": -1})
+	req := httptest.NewRequest("POST", "/users/setMaxReviews", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetMaxReviews(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestSetChatHandle(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	tests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantStatus int
+	}{
+		{"set", map[string]interface{}{"user_id": "u1", "chat_handle": " @alice "}, http.StatusOK},
+		{"missing handle", map[string]interface{}{"user_id": "u1"}, http.StatusBadRequest},
+		{"unknown user", map[string]interface{}{"user_id": "u9", "chat_handle": "@ghost"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest("POST", "/users/setChatHandle", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handlers.SetChatHandle(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+	if got := repo.users["u1"].ChatHandle; got != "@alice" {
+		t.Fatalf("expected chat handle @alice, got %q", got)
+	}
+}
+
+func TestSetDigest(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	tests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantStatus int
+	}{
+		{"set email", map[string]interface{}{"user_id": "u1", "email": "alice@example.com"}, http.StatusOK},
+		{"opt out keeps email", map[string]interface{}{"user_id": "u1", "digest_opt_out": true}, http.StatusOK},
+		{"invalid email", map[string]interface{}{"user_id": "u1", "email": "Alice <alice@example.com>"}, http.StatusBadRequest},
+		{"nothing to change", map[string]interface{}{"user_id": "u1"}, http.StatusBadRequest},
+		{"unknown user", map[string]interface{}{"user_id": "u9", "digest_opt_out": true}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest("POST", "/users/setDigest", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handlers.SetDigest(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+	if got := repo.users["u1"]; got.Email != "alice@example.com" || !got.DigestOptOut {
+		t.Fatalf("expected email kept and digest opted out, got %+v", got)
+	}
+}
+
+func TestSetTags_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"user_id": "u1", "tags": []string{" DB ", "backend", "db"}})
+	req := httptest.NewRequest("POST", "/users/setTags", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetTags(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := repo.users["u1"].Tags; len(got) != 2 || got[0] != "db" || got[1] != "backend" {
+		t.Fatalf("expected normalized tags [db backend], got %v", got)
+	}
+}
+
+func TestSetTags_EmptyTag(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"user_id": "u1", "tags": []string{"db", " "}})
+	req := httptest.NewRequest("POST", "/users/setTags", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SetTags(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestAddAbsence_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"user_id":   "u1",
+		"starts_at": "2025-07-01T00:00:00Z",
+		"ends_at":   "2025-07-15T00:00:00Z",
+		"reason":    "vacation",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/users/addAbsence", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.AddAbsence(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", w.Code)
+	}
+	if len(repo.absences) != 1 {
+		t.Fatalf("expected absence to be stored")
+	}
+}
+
+func TestAddAbsence_ReassignScheduled(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	start := time.Now().Add(48 * time.Hour).UTC()
+	payload := map[string]interface{}{
+		"user_id":               "u1",
+		"starts_at":             start.Format(time.RFC3339),
+		"ends_at":               start.Add(7 * 24 * time.Hour).Format(time.RFC3339),
+		"reassign_open_reviews": true,
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/users/addAbsence", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.AddAbsence(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", w.Code)
+	}
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if _, ok := resp["reassignment"]; ok {
+		t.Fatalf("expected no immediate reassignment for a planned absence, got %v", resp["reassignment"])
+	}
+	stored := repo.absences[1]
+	if !stored.ReassignOpenReviews || stored.ReassignedAt != nil {
+		t.Fatalf("expected pending reassignment to be stored, got %+v", stored)
+	}
+}
+
+func TestAddAbsence_InvalidPeriod(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"user_id":   "u1",
+		"starts_at": "2025-07-15T00:00:00Z",
+		"ends_at":   "2025-07-01T00:00:00Z",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/users/addAbsence", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.AddAbsence(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestGetAbsences_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	repo.absences[1] = domain.Absence{ID: 1, UserID: "u1"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	req := httptest.NewRequest("GET", "/users/getAbsences?user_id=u1", nil)
+	w := httptest.NewRecorder()
+	handlers.GetAbsences(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Absences []domain.Absence `json:"absences"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Absences) != 1 {
+		t.Fatalf("expected 1 absence, got %d", len(response.Absences))
+	}
+}
+
+func TestDeleteAbsence_NotFound(t *testing.T) {
+	repo := newMockRepo()
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"absence_id": 42})
+	req := httptest.NewRequest("POST", "/users/deleteAbsence", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.DeleteAbsence(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestCreatePR_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+	repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"pull_request_id":   "pr1",
+		"pull_request_name": "feat",
+		"author_id":         "u1",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/pullRequest/create", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.CreatePR(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", w.Code)
+	}
+}
+
+func TestCreatePR_MissingFields(t *testing.T) {
+	repo := newMockRepo()
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"pull_request_id": "pr1",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/pullRequest/create", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.CreatePR(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreatePR_AuthorNotFound(t *testing.T) {
+	repo := newMockRepo()
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"pull_request_id":   "pr1",
+		"pull_request_name": "feat",
+		"author_id":         "nonexistent",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/pullRequest/create", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.CreatePR(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestReassign_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+	repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+	repo.users["u3"] = domain.User{ID: "u3", Username: "carl", TeamID: 1, IsActive: true}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "fix", AuthorID: "u1", Status: "OPEN"}
+	repo.reviewers["pr1"] = []string{"u2"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"pull_request_id": "pr1",
+		"old_user_id":     "u2",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/pullRequest/reassign", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.Reassign(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+}
+
+func TestReassign_PRNotFound(t *testing.T) {
+	repo := newMockRepo()
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"pull_request_id": "nonexistent",
+		"old_user_id":     "u1",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/pullRequest/reassign", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.Reassign(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestMerge_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "merge", AuthorID: "u1", Status: "OPEN"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	payload := map[string]interface{}{
+		"pull_request_id": "pr1",
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/pullRequest/merge", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.Merge(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+}
+
 func TestMerge_PRNotFound(t *testing.T) {
 	repo := newMockRepo()
 	ucase := uc.NewPRUsecase(repo)
@@ -549,6 +1419,50 @@ func TestMerge_PRNotFound(t *testing.T) {
 	}
 }
 
+func TestMerge_ClosedPR(t *testing.T) {
+	repo := newMockRepo()
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "merge", AuthorID: "u1", Status: domain.StatusClosed}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1"})
+	req := httptest.NewRequest("POST", "/pullRequest/merge", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.Merge(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", w.Code)
+	}
+	var response map[string]map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response["error"]["code"] != codeInvalidTransition {
+		t.Fatalf("expected %s, got %s", codeInvalidTransition, response["error"]["code"])
+	}
+}
+
+func TestClose_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: domain.StatusOpen}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1"})
+	req := httptest.NewRequest("POST", "/pullRequest/close", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.Close(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if repo.prs["pr1"].Status != domain.StatusClosed {
+		t.Fatalf("expected CLOSED got %s", repo.prs["pr1"].Status)
+	}
+}
+
 func TestGetUserReviews_Success(t *testing.T) {
 	repo := newMockRepo()
 	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
@@ -585,7 +1499,7 @@ func TestGetUserReviews_MissingParam(t *testing.T) {
 func TestGetStats_Success(t *testing.T) {
 	repo := newMockRepo()
 	repo.stats = []repository.ReviewerStat{
-		{UserID: "u1", Username: "alice", Count: 5},
+		{UserID: "u1", Username: "alice", Count: 5, ReviewedCount: 2, AvgTimeToFirstReview: 90 * time.Minute},
 		{UserID: "u2", Username: "bob", Count: 3},
 	}
 	ucase := uc.NewPRUsecase(repo)
@@ -611,4 +1525,441 @@ func TestGetStats_Success(t *testing.T) {
 	if len(stats) != 2 {
 		t.Fatalf("expected 2 stats, got %d", len(stats))
 	}
+	if got := stats[0].(map[string]interface{})["avg_time_to_first_review_seconds"]; got != float64(5400) {
+		t.Fatalf("expected 5400 seconds, got %v", got)
+	}
+	if got := stats[1].(map[string]interface{})["avg_time_to_first_review_seconds"]; got != nil {
+		t.Fatalf("expected null without reviews, got %v", got)
+	}
+}
+
+func TestGetOverdue_Success(t *testing.T) {
+	repo := newMockRepo()
+	assigned := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	repo.overdue = []domain.OverduePR{{
+		ID: "pr1", Title: "feat", AuthorID: "u1", Status: domain.StatusOpen,
+		Reviewers: []domain.OverdueReview{{ReviewerID: "u2", AssignedAt: assigned, DueAt: assigned.Add(24 * time.Hour)}},
+	}}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	req := httptest.NewRequest("GET", "/pullRequest/overdue", nil)
+	w := httptest.NewRecorder()
+	handlers.GetOverdue(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		PullRequests []domain.OverduePR `json:"pull_requests"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.PullRequests) != 1 || response.PullRequests[0].Reviewers[0].ReviewerID != "u2" {
+		t.Fatalf("unexpected overdue PRs: %+v", response.PullRequests)
+	}
+}
+
+func TestGetPR(t *testing.T) {
+	repo := newMockRepo()
+	created := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "fix", AuthorID: "u1", Status: domain.StatusOpen, CreatedAt: created}
+	repo.reviewers["pr1"] = []string{"u2"}
+	// pr0 существовал до потока событий, его история известна только с 2026-10-14
+	repo.prs["pr0"] = domain.PullRequest{ID: "pr0", Title: "old", AuthorID: "u1", Status: domain.StatusClosed, CreatedAt: created.AddDate(0, -1, 0)}
+	repo.historyFrom = map[string]time.Time{"pr0": created.AddDate(0, 0, 2)}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	router := NewRouter(NewHandlers(ucase, repo, logger))
+
+	for target, want := range map[string]int{
+		"/pullRequest/get?pull_request_id=pr0&at=2026-10-13T00:00:00Z":        http.StatusUnprocessableEntity,
+		"/pullRequest/get?pull_request_id=pr1":                                http.StatusOK,
+		"/pullRequest/get?pull_request_id=pr1&at=2026-10-13T00:00:00Z":        http.StatusOK,
+		"/pullRequest/get?pull_request_id=pr1&at=2026-10-12T11:00:00%2B03:00": http.StatusNotFound,
+		"/pullRequest/get?pull_request_id=pr1&at=yesterday":                   http.StatusBadRequest,
+		"/pullRequest/get?pull_request_id=missing":                            http.StatusNotFound,
+		"/pullRequest/get": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != want {
+			t.Fatalf("%s: expected status %d, got %d", target, want, w.Code)
+		}
+		if want != http.StatusOK {
+			continue
+		}
+		var response struct {
+			PR domain.PullRequest `json:"pr"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if response.PR.ID != "pr1" || response.PR.Status != domain.StatusOpen {
+			t.Fatalf("%s: unexpected PR: %+v", target, response.PR)
+		}
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   string
+	}{
+		{"anonymous", "", "", "api"},
+		{"advisory name", "", "alice", "api:alice"},
+		{"wrong token", "guess", "alice", "api:alice"},
+		{"admin token", "secret", "", "admin"},
+		{"admin token with name", "secret", "alice", "admin:alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandlers(nil, newMockRepo(), infra.NewStdLogger())
+			h.AdminToken = "secret"
+			var got string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = domain.ActorFrom(r.Context())
+			})
+			req := httptest.NewRequest("GET", "/health", nil)
+			req.Header.Set(adminTokenHeader, tt.token)
+			req.Header.Set(actorHeader, tt.header)
+			h.withActor(next).ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Fatalf("expected actor %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetPRHistory(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+	repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+	repo.users["u3"] = domain.User{ID: "u3", Username: "carl", TeamID: 1, IsActive: true}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "fix", AuthorID: "u1", Status: "OPEN"}
+	repo.reviewers["pr1"] = []string{"u2"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	router := NewRouter(NewHandlers(ucase, repo, logger))
+
+	body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1", "old_user_id": "u2"})
+	req := httptest.NewRequest("POST", "/pullRequest/reassign", bytes.NewReader(body))
+	req.Header.Set("X-Actor", "alice")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/pullRequest/history?pull_request_id=pr1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Events []domain.AssignmentEvent `json:"events"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Events) != 1 {
+		t.Fatalf("expected 1 event, got %+v", response.Events)
+	}
+	e := response.Events[0]
+	// X-Actor не подтверждён и пишется после api
+	if e.Actor != "api:alice" || e.OldReviewerID != "u2" || e.NewReviewerID != "u3" || e.Reason != domain.ReasonManualReassign {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	for target, want := range map[string]int{
+		"/pullRequest/history":                         http.StatusBadRequest,
+		"/pullRequest/history?pull_request_id=missing": http.StatusNotFound,
+	} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != want {
+			t.Fatalf("%s: expected status %d, got %d", target, want, w.Code)
+		}
+	}
+}
+
+func TestSubmitReview_Success(t *testing.T) {
+	repo := newMockRepo()
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", IsActive: true}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u2", Status: domain.StatusOpen}
+	repo.reviewers["pr1"] = []string{"u1"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1", "reviewer_id": "u1", "decision": "APPROVED"})
+	req := httptest.NewRequest("POST", "/pullRequest/review", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SubmitReview(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/users/getReview?user_id=u1", nil)
+	w = httptest.NewRecorder()
+	handlers.GetUserReviews(w, req)
+
+	var response struct {
+		PullRequests []struct {
+			Decision string `json:"decision"`
+		} `json:"pull_requests"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.PullRequests) != 1 || response.PullRequests[0].Decision != "APPROVED" {
+		t.Fatalf("expected APPROVED decision, got %+v", response.PullRequests)
+	}
+}
+
+func TestSubmitReview_InvalidDecision(t *testing.T) {
+	repo := newMockRepo()
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u2", Status: domain.StatusOpen}
+	repo.reviewers["pr1"] = []string{"u1"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+
+	body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1", "reviewer_id": "u1", "decision": "LGTM"})
+	req := httptest.NewRequest("POST", "/pullRequest/review", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.SubmitReview(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func newMergePolicyHandlers(requiredApprovals int) (*mockRepo, *Handlers) {
+	repo := newMockRepo()
+	repo.teams["backend"] = domain.Team{ID: 1, Name: "backend", RequiredApprovals: requiredApprovals}
+	repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+	repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "merge", AuthorID: "u1", Status: domain.StatusOpen}
+	repo.reviewers["pr1"] = []string{"u2"}
+	ucase := uc.NewPRUsecase(repo)
+	logger := infra.NewStdLogger()
+	handlers := NewHandlers(ucase, repo, logger)
+	handlers.AdminToken = "secret"
+	return repo, handlers
+}
+
+func TestMerge_BlockedByPolicy(t *testing.T) {
+	repo, handlers := newMergePolicyHandlers(1)
+
+	body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1"})
+	req := httptest.NewRequest("POST", "/pullRequest/merge", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	handlers.Merge(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", w.Code)
+	}
+	var response struct {
+		Error struct {
+			Code  string   `json:"code"`
+			Unmet []string `json:"unmet"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Error.Code != codeMergeBlocked || len(response.Error.Unmet) != 1 {
+		t.Fatalf("expected %s with one unmet condition, got %+v", codeMergeBlocked, response.Error)
+	}
+	if repo.prs["pr1"].Status != domain.StatusOpen {
+		t.Fatalf("expected OPEN got %s", repo.prs["pr1"].Status)
+	}
+}
+
+func TestMerge_Force(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		forcedBy string
+		code     int
+	}{
+		{"no token", "", "u1", http.StatusForbidden},
+		{"wrong token", "guess", "u1", http.StatusForbidden},
+		{"admin token", "secret", "u1", http.StatusOK},
+		{"unknown forced_by", "secret", "admin", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, handlers := newMergePolicyHandlers(1)
+
+			body, _ := json.Marshal(map[string]interface{}{"pull_request_id": "pr1", "force": true, "forced_by": tt.forcedBy})
+			req := httptest.NewRequest("POST", "/pullRequest/merge", bytes.NewReader(body))
+			req.Header.Set(adminTokenHeader, tt.token)
+			w := httptest.NewRecorder()
+			handlers.Merge(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d", tt.code, w.Code)
+			}
+			if tt.code == http.StatusOK && repo.prs["pr1"].MergeForcedBy != "u1" {
+				t.Fatalf("expected force merge recorded, got %q", repo.prs["pr1"].MergeForcedBy)
+			}
+			if tt.code != http.StatusOK && repo.prs["pr1"].Status != domain.StatusOpen {
+				t.Fatalf("expected PR to stay OPEN, got %s", repo.prs["pr1"].Status)
+			}
+		})
+	}
+}
+
+func TestAddWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		code    int
+	}{
+		{"success", map[string]interface{}{"url": "https://hooks.example.com/pr", "secret": "s", "events": []string{"pr.merged"}}, http.StatusCreated},
+		{"missing secret", map[string]interface{}{"url": "https://hooks.example.com/pr"}, http.StatusBadRequest},
+		{"relative url", map[string]interface{}{"url": "/pr", "secret": "s"}, http.StatusBadRequest},
+		{"unknown event", map[string]interface{}{"url": "https://hooks.example.com/pr", "secret": "s", "events": []string{"pr.deleted"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			ucase := uc.NewPRUsecase(repo)
+			logger := infra.NewStdLogger()
+			handlers := NewHandlers(ucase, repo, logger)
+
+			body, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest("POST", "/webhooks/add", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handlers.AddWebhook(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d", tt.code, w.Code)
+			}
+			if tt.code == http.StatusCreated && bytes.Contains(w.Body.Bytes(), []byte(`"secret"`)) {
+				t.Fatalf("secret must not be returned: %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestLinkAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]interface{}
+		code    int
+	}{
+		{"success", map[string]interface{}{"provider": "github", "login": "alice-dev", "user_id": "u1"}, http.StatusOK},
+		{"unknown provider", map[string]interface{}{"provider": "bitbucket", "login": "alice-dev", "user_id": "u1"}, http.StatusBadRequest},
+		{"missing login", map[string]interface{}{"provider": "github", "user_id": "u1"}, http.StatusBadRequest},
+		{"unknown user", map[string]interface{}{"provider": "github", "login": "alice-dev", "user_id": "u9"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+			handlers := NewHandlers(uc.NewPRUsecase(repo), repo, infra.NewStdLogger())
+
+			body, _ := json.Marshal(tt.payload)
+			req := httptest.NewRequest("POST", "/integrations/accounts/link", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			handlers.LinkAccount(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d", tt.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestGitHubWebhook(t *testing.T) {
+	const secret = "gh-secret"
+	opened := []byte(`{"action":"opened","number":7,"pull_request":{"number":7,"title":"feat","draft":false,"merged":false,"user":{"login":"Alice-Dev"}},"repository":{"full_name":"acme/backend"},"sender":{"login":"Alice-Dev"}}`)
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		linked    bool
+		code      int
+	}{
+		{"creates PR", secret, webhook.Sign(secret, opened), true, http.StatusOK},
+		{"invalid signature", secret, webhook.Sign("other", opened), true, http.StatusUnauthorized},
+		{"not configured", "", webhook.Sign(secret, opened), true, http.StatusForbidden},
+		{"unknown login", secret, webhook.Sign(secret, opened), false, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			repo.users["u1"] = domain.User{ID: "u1", Username: "alice", TeamID: 1, IsActive: true}
+			repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+			if tt.linked {
+				_, _ = repo.LinkExternalAccount(context.Background(), domain.ExternalAccount{Provider: domain.ProviderGitHub, Login: "Alice-Dev", UserID: "u1"})
+			}
+			handlers := NewHandlers(uc.NewPRUsecase(repo), repo, infra.NewStdLogger())
+			handlers.GitHubSecret = tt.secret
+
+			req := httptest.NewRequest("POST", "/integrations/github/webhook", bytes.NewReader(opened))
+			req.Header.Set("X-GitHub-Event", "pull_request")
+			req.Header.Set("X-Hub-Signature-256", tt.signature)
+			w := httptest.NewRecorder()
+			handlers.GitHubWebhook(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			_, created := repo.prs["acme/backend#7"]
+			if created != (tt.code == http.StatusOK) {
+				t.Fatalf("expected PR created=%v, got %v", tt.code == http.StatusOK, created)
+			}
+		})
+	}
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	const token = "gl-token"
+	opened := []byte(`{"object_kind":"merge_request","user":{"username":"carol.i"},"project":{"path_with_namespace":"payments/billing"},"object_attributes":{"iid":3,"title":"feat","action":"open"}}`)
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		code   int
+	}{
+		{"creates PR", token, token, http.StatusOK},
+		{"invalid token", token, "other", http.StatusUnauthorized},
+		{"not configured", "", token, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			repo.users["u1"] = domain.User{ID: "u1", Username: "carol", TeamID: 1, IsActive: true}
+			repo.users["u2"] = domain.User{ID: "u2", Username: "bob", TeamID: 1, IsActive: true}
+			_, _ = repo.LinkExternalAccount(context.Background(), domain.ExternalAccount{Provider: domain.ProviderGitLab, Login: "carol.i", UserID: "u1"})
+			handlers := NewHandlers(uc.NewPRUsecase(repo), repo, infra.NewStdLogger())
+			handlers.GitLabToken = tt.secret
+
+			req := httptest.NewRequest("POST", "/integrations/gitlab/webhook", bytes.NewReader(opened))
+			req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+			req.Header.Set("X-Gitlab-Token", tt.token)
+			w := httptest.NewRecorder()
+			handlers.GitLabWebhook(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			pr, created := repo.prs["payments/billing!3"]
+			if created != (tt.code == http.StatusOK) {
+				t.Fatalf("expected PR created=%v, got %v", tt.code == http.StatusOK, created)
+			}
+			if created && pr.AuthorID != "u1" {
+				t.Fatalf("expected author u1, got %s", pr.AuthorID)
+			}
+		})
+	}
 }
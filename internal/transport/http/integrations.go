@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/integration"
+	"github.com/you/pr-assign-avito/internal/integration/github"
+	"github.com/you/pr-assign-avito/internal/integration/gitlab"
+	"github.com/you/pr-assign-avito/internal/repository"
+	uc "github.com/you/pr-assign-avito/internal/usecase"
+)
+
+// maxWebhookPayload - предельный размер тела входящего вебхука
+const maxWebhookPayload = 25 << 20
+
+func (h *Handlers) GitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.GitHubSecret == "" {
+		errorResp(w, http.StatusForbidden, codeForbidden, "github integration is not configured")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		h.Log.Errorf("GitHubWebhook: failed to read request body: %v", err)
+		badRequest(w, "failed to read body")
+		return
+	}
+	if !github.VerifySignature(h.GitHubSecret, body, r.Header.Get(github.HeaderSignature)) {
+		errorResp(w, http.StatusUnauthorized, codeUnauthorized, "invalid signature")
+		return
+	}
+
+	res, err := h.GitHub.Handle(r.Context(), r.Header.Get(github.HeaderEvent), body)
+	if err != nil {
+		h.integrationError(w, "GitHubWebhook", r.Header.Get(github.HeaderDelivery), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": res})
+}
+
+func (h *Handlers) GitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	if h.GitLabToken == "" {
+		errorResp(w, http.StatusForbidden, codeForbidden, "gitlab integration is not configured")
+		return
+	}
+	if !gitlab.VerifyToken(h.GitLabToken, r.Header.Get(gitlab.HeaderToken)) {
+		errorResp(w, http.StatusUnauthorized, codeUnauthorized, "invalid token")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		h.Log.Errorf("GitLabWebhook: failed to read request body: %v", err)
+		badRequest(w, "failed to read body")
+		return
+	}
+
+	res, err := h.GitLab.Handle(r.Context(), r.Header.Get(gitlab.HeaderEvent), body)
+	if err != nil {
+		h.integrationError(w, "GitLabWebhook", r.Header.Get(gitlab.HeaderEventUUID), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"result": res})
+}
+
+// integrationError отвечает на ошибку обработки вебхука внешней системы
+func (h *Handlers) integrationError(w http.ResponseWriter, op, delivery string, err error) {
+	switch {
+	case errors.Is(err, integration.ErrUnknownLogin):
+		errorResp(w, http.StatusUnprocessableEntity, codeUnknownLogin, err.Error())
+	case errors.Is(err, uc.ErrNotEnoughReviewers):
+		errorResp(w, http.StatusConflict, codeNotEnoughReviewers, "not enough available reviewers in team")
+	case errors.Is(err, uc.ErrInvalidTransition):
+		errorResp(w, http.StatusConflict, codeInvalidTransition, "status transition not allowed")
+	case errors.Is(err, uc.ErrPRMerged):
+		errorResp(w, http.StatusConflict, codePRMerged, "PR is merged")
+	default:
+		h.Log.Errorf("%s: failed to handle delivery %s: %v", op, delivery, err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+	}
+}
+
+func (h *Handlers) LinkAccount(w http.ResponseWriter, r *http.Request) {
+	var payload domain.ExternalAccount
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("LinkAccount: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.Provider == "" || payload.Login == "" || payload.UserID == "" {
+		badRequest(w, "provider, login and user_id required")
+		return
+	}
+	if !domain.IsValidProvider(payload.Provider) {
+		badRequest(w, "unknown provider "+payload.Provider)
+		return
+	}
+	if _, err := h.Repo.GetUserByID(r.Context(), payload.UserID); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "user not found")
+			return
+		}
+		h.Log.Errorf("LinkAccount: failed to get user: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	account, err := h.Repo.LinkExternalAccount(r.Context(), payload)
+	if err != nil {
+		h.Log.Errorf("LinkAccount: failed to link account: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"account": account})
+}
+
+func (h *Handlers) UnlinkAccount(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Provider string `json:"provider"`
+		Login    string `json:"login"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("UnlinkAccount: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.Provider == "" || payload.Login == "" {
+		badRequest(w, "provider and login required")
+		return
+	}
+	if err := h.Repo.UnlinkExternalAccount(r.Context(), payload.Provider, payload.Login); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "account not found")
+			return
+		}
+		h.Log.Errorf("UnlinkAccount: failed to unlink account: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"provider": payload.Provider, "login": payload.Login})
+}
@@ -2,20 +2,77 @@ package http
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+// actorHeader - заголовок, которым клиент называет инициатора запроса для журнала
+// назначений. Он ничем не подтверждён и пишется только после проверенной части.
+const actorHeader = "X-Actor"
+
+const (
+	// actorAPI - инициатор запросов без токена администратора
+	actorAPI = "api"
+	// actorAdmin - инициатор запросов с верным токеном администратора
+	actorAdmin = "admin"
 )
 
+// withActor кладёт в контекст запроса инициатора изменений: admin, если запрос
+// подписан токеном администратора, иначе api; имя из actorHeader добавляется
+// через двоеточие как необязательное пояснение.
+func (h *Handlers) withActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := actorAPI
+		if h.isAdmin(r) {
+			actor = actorAdmin
+		}
+		if name := strings.TrimSpace(r.Header.Get(actorHeader)); name != "" {
+			actor += ":" + name
+		}
+		next.ServeHTTP(w, r.WithContext(domain.WithActor(r.Context(), actor)))
+	})
+}
+
 func NewRouter(h *Handlers) http.Handler {
 	r := mux.NewRouter()
+	r.Use(h.withActor)
 	r.HandleFunc("/health", h.Health).Methods("GET")
 	r.HandleFunc("/team/add", h.AddTeam).Methods("POST")
 	r.HandleFunc("/team/get", h.GetTeam).Methods("GET")
+	r.HandleFunc("/team/update", h.UpdateTeam).Methods("POST")
+	r.HandleFunc("/team/setCodeowners", h.SetCodeowners).Methods("POST")
+	r.HandleFunc("/team/deactivateUsers", h.DeactivateTeamUsers).Methods("POST")
 	r.HandleFunc("/users/setIsActive", h.SetIsActive).Methods("POST")
+	r.HandleFunc("/users/setMaxReviews", h.SetMaxReviews).Methods("POST")
+	r.HandleFunc("/users/setTags", h.SetTags).Methods("POST")
+	r.HandleFunc("/users/setChatHandle", h.SetChatHandle).Methods("POST")
+	r.HandleFunc("/users/setDigest", h.SetDigest).Methods("POST")
 	r.HandleFunc("/users/getReview", h.GetUserReviews).Methods("GET")
+	r.HandleFunc("/users/addAbsence", h.AddAbsence).Methods("POST")
+	r.HandleFunc("/users/getAbsences", h.GetAbsences).Methods("GET")
+	r.HandleFunc("/users/updateAbsence", h.UpdateAbsence).Methods("POST")
+	r.HandleFunc("/users/deleteAbsence", h.DeleteAbsence).Methods("POST")
 	r.HandleFunc("/pullRequest/create", h.CreatePR).Methods("POST")
 	r.HandleFunc("/pullRequest/reassign", h.Reassign).Methods("POST")
+	r.HandleFunc("/pullRequest/review", h.SubmitReview).Methods("POST")
 	r.HandleFunc("/pullRequest/merge", h.Merge).Methods("POST")
+	r.HandleFunc("/pullRequest/close", h.Close).Methods("POST")
+	r.HandleFunc("/pullRequest/reopen", h.Reopen).Methods("POST")
+	r.HandleFunc("/pullRequest/markDraft", h.MarkDraft).Methods("POST")
+	r.HandleFunc("/pullRequest/markReady", h.MarkReady).Methods("POST")
+	r.HandleFunc("/pullRequest/overdue", h.GetOverdue).Methods("GET")
+	r.HandleFunc("/pullRequest/get", h.GetPR).Methods("GET")
+	r.HandleFunc("/pullRequest/history", h.GetPRHistory).Methods("GET")
 	r.HandleFunc("/statistics/reviewers", h.GetStats).Methods("GET")
+	r.HandleFunc("/webhooks/add", h.AddWebhook).Methods("POST")
+	r.HandleFunc("/webhooks/list", h.ListWebhooks).Methods("GET")
+	r.HandleFunc("/webhooks/delete", h.DeleteWebhook).Methods("POST")
+	r.HandleFunc("/integrations/accounts/link", h.LinkAccount).Methods("POST")
+	r.HandleFunc("/integrations/accounts/unlink", h.UnlinkAccount).Methods("POST")
+	r.HandleFunc("/integrations/github/webhook", h.GitHubWebhook).Methods("POST")
+	r.HandleFunc("/integrations/gitlab/webhook", h.GitLabWebhook).Methods("POST")
 	return r
 }
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+func (h *Handlers) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("AddWebhook: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.URL == "" || payload.Secret == "" {
+		badRequest(w, "url and secret required")
+		return
+	}
+	if !isHTTPURL(payload.URL) {
+		badRequest(w, "url must be an absolute http(s) URL")
+		return
+	}
+	for _, e := range payload.Events {
+		if !domain.IsValidEventType(e) {
+			badRequest(w, "unknown event type "+e)
+			return
+		}
+	}
+	hook, err := h.Repo.AddWebhook(r.Context(), domain.Webhook{URL: payload.URL, Secret: payload.Secret, Events: payload.Events})
+	if err != nil {
+		h.Log.Errorf("AddWebhook: failed to add webhook: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"webhook": hook})
+}
+
+// isHTTPURL сообщает, что s - абсолютный http(s) URL
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (h *Handlers) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.Repo.ListWebhooks(r.Context())
+	if err != nil {
+		h.Log.Errorf("ListWebhooks: failed to list webhooks: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"webhooks": hooks})
+}
+
+func (h *Handlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		WebhookID int `json:"webhook_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.Log.Errorf("DeleteWebhook: failed to decode request body: %v", err)
+		badRequest(w, "invalid json")
+		return
+	}
+	if payload.WebhookID == 0 {
+		badRequest(w, "webhook_id required")
+		return
+	}
+	if err := h.Repo.DeleteWebhook(r.Context(), payload.WebhookID); err != nil {
+		if err == repository.ErrNotFound {
+			notFound(w, "webhook not found")
+			return
+		}
+		h.Log.Errorf("DeleteWebhook: failed to delete webhook: %v", err)
+		errorResp(w, http.StatusInternalServerError, codeNotFound, "internal server error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"webhook_id": payload.WebhookID})
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// EscalateStaleReviews эскалирует назначения, по которым ревьювер не ответил дольше
+// порога своей команды: ревьювер заменяется через reassignReviewer либо к PR
+// добавляется ещё один ревьювер. Неудачи попадают в отчёт и повторяются на следующем проходе.
+func (u *PRUsecase) EscalateStaleReviews(ctx context.Context, now time.Time) (domain.EscalationReport, error) {
+	report := domain.EscalationReport{Escalated: []domain.Escalation{}, Failed: []domain.ReassignFailure{}}
+	stale, err := u.Repo.GetStaleReviews(ctx, now)
+	if err != nil {
+		return report, err
+	}
+	for _, s := range stale {
+		var newID string
+		if s.Action == domain.EscalationAddReviewer {
+			newID, err = u.addEscalationReviewer(ctx, s.PullRequestID, s.ReviewerID)
+		} else {
+			newID, err = u.reassignReviewer(ctx, s.PullRequestID, s.ReviewerID, domain.ReasonEscalationReassign)
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, domain.ReassignFailure{
+				PullRequestID: s.PullRequestID,
+				ReviewerID:    s.ReviewerID,
+				Reason:        err.Error(),
+			})
+			continue
+		}
+		report.Escalated = append(report.Escalated, domain.Escalation{
+			PullRequestID: s.PullRequestID,
+			ReviewerID:    s.ReviewerID,
+			Action:        s.Action,
+			NewReviewerID: newID,
+		})
+	}
+	return report, nil
+}
+
+// addEscalationReviewer добавляет к PR ревьювера из команды автора в помощь зависшему.
+func (u *PRUsecase) addEscalationReviewer(ctx context.Context, prID, staleReviewerID string) (string, error) {
+	pr, err := u.Repo.GetPR(ctx, prID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	if err := reviewersLockedErr(pr.Status); err != nil {
+		return "", err
+	}
+	current, err := u.Repo.GetPRReviewers(ctx, prID)
+	if err != nil {
+		return "", err
+	}
+	author, err := u.Repo.GetUserByID(ctx, pr.AuthorID)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	team, err := u.Repo.GetTeamByID(ctx, author.TeamID)
+	if err != nil {
+		return "", err
+	}
+	picked, _, err := u.pickReviewers(ctx, team, append([]string{author.ID}, current...), pr.Tags, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(picked) == 0 {
+		return "", ErrNoCandidate
+	}
+
+	if err := u.Repo.AddEscalationReviewer(ctx, prID, staleReviewerID, picked[0]); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return "", ErrNotFound
+		case repository.ErrPRMerged:
+			return "", ErrPRMerged
+		case repository.ErrPRClosed:
+			return "", ErrPRClosed
+		case repository.ErrNotAssigned:
+			return "", ErrNotAssigned
+		default:
+			return "", err
+		}
+	}
+	return picked[0], nil
+}
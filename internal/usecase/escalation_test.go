@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+func setupStaleReview(t *testing.T, action domain.EscalationAction) *memRepo {
+	t.Helper()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: domain.StatusOpen}, []string{"u2"})
+	repo.stale = []domain.StaleReview{{PullRequestID: "pr1", ReviewerID: "u2", Action: action}}
+	return repo
+}
+
+func TestEscalateStaleReviews_Reassign(t *testing.T) {
+	repo := setupStaleReview(t, domain.EscalationReassign)
+	u := NewPRUsecase(repo)
+
+	report, err := u.EscalateStaleReviews(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(report.Escalated) != 1 || report.Escalated[0].NewReviewerID != "u3" {
+		t.Fatalf("expected u2 replaced by u3, got %+v", report)
+	}
+	if got := repo.reviewers["pr1"]; len(got) != 1 || got[0] != "u3" {
+		t.Fatalf("expected reviewers [u3], got %v", got)
+	}
+}
+
+func TestEscalateStaleReviews_AddReviewer(t *testing.T) {
+	repo := setupStaleReview(t, domain.EscalationAddReviewer)
+	u := NewPRUsecase(repo)
+
+	report, err := u.EscalateStaleReviews(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(report.Escalated) != 1 || report.Escalated[0].NewReviewerID != "u3" {
+		t.Fatalf("expected u3 added, got %+v", report)
+	}
+	if got := repo.reviewers["pr1"]; len(got) != 2 || got[0] != "u2" || got[1] != "u3" {
+		t.Fatalf("expected reviewers [u2 u3], got %v", got)
+	}
+}
+
+func TestEscalateStaleReviews_NoCandidate(t *testing.T) {
+	repo := setupStaleReview(t, domain.EscalationAddReviewer)
+	repo.reviewers["pr1"] = []string{"u2", "u3"}
+	u := NewPRUsecase(repo)
+
+	report, err := u.EscalateStaleReviews(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(report.Escalated) != 0 || len(report.Failed) != 1 || report.Failed[0].Reason != ErrNoCandidate.Error() {
+		t.Fatalf("expected one failure with %v, got %+v", ErrNoCandidate, report)
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+// Notifier сообщает людям о назначениях ревьюверов, переназначениях и merge
+// (реализуется notify.Chat). Ошибки доставки обрабатывает сам Notifier.
+type Notifier interface {
+	ReviewersAssigned(ctx context.Context, prID string, reviewerIDs []string)
+	ReviewerReassigned(ctx context.Context, r domain.Replacement)
+	PRMerged(ctx context.Context, pr domain.PullRequest)
+}
+
+// NotifierSink вызывает Notifier по событиям, которые PRUsecase записывает в outbox
+// при назначении, переназначении и merge. Уведомления уходят из relay outbox уже
+// после фиксации изменений, поэтому медленный Notifier не задерживает запросы.
+type NotifierSink struct {
+	Notifier Notifier
+}
+
+func NewNotifierSink(n Notifier) *NotifierSink {
+	return &NotifierSink{Notifier: n}
+}
+
+func (s *NotifierSink) Name() string {
+	return "notifier"
+}
+
+// Deliver передаёт Notifier события reviewer.assigned, reviewer.reassigned и pr.merged.
+func (s *NotifierSink) Deliver(ctx context.Context, e domain.Event) error {
+	switch e.Type {
+	case domain.EventReviewerAssigned, domain.EventReviewerReassigned:
+		var a domain.ReviewerAssignment
+		if err := decodeEvent(e, &a); err != nil {
+			return err
+		}
+		if e.Type == domain.EventReviewerAssigned {
+			s.Notifier.ReviewersAssigned(ctx, a.PullRequestID, []string{a.ReviewerID})
+			return nil
+		}
+		s.Notifier.ReviewerReassigned(ctx, domain.Replacement{
+			PullRequestID: a.PullRequestID, OldReviewerID: a.OldReviewerID, NewReviewerID: a.ReviewerID,
+		})
+	case domain.EventPRMerged:
+		var pr domain.PullRequest
+		if err := decodeEvent(e, &pr); err != nil {
+			return err
+		}
+		s.Notifier.PRMerged(ctx, pr)
+	}
+	return nil
+}
+
+// decodeEvent раскладывает данные события e в v
+func decodeEvent(e domain.Event, v interface{}) error {
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("decode %s event %s: %w", e.Type, e.ID, err)
+	}
+	return nil
+}
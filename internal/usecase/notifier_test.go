@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+type recordingNotifier struct {
+	calls []string
+}
+
+func (n *recordingNotifier) ReviewersAssigned(ctx context.Context, prID string, reviewerIDs []string) {
+	n.calls = append(n.calls, "assigned "+prID+" "+strings.Join(reviewerIDs, ","))
+}
+
+func (n *recordingNotifier) ReviewerReassigned(ctx context.Context, r domain.Replacement) {
+	n.calls = append(n.calls, "reassigned "+r.PullRequestID+" "+r.OldReviewerID+"->"+r.NewReviewerID)
+}
+
+func (n *recordingNotifier) PRMerged(ctx context.Context, pr domain.PullRequest) {
+	n.calls = append(n.calls, "merged "+pr.ID)
+}
+
+func TestNotifierSink_NotifiesOnOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	n := &recordingNotifier{}
+	sink := NewNotifierSink(n)
+
+	events := []domain.Event{
+		domain.NewEvent(domain.EventPRCreated, domain.PullRequest{ID: "pr1", AuthorID: "u1"}),
+		domain.NewReviewerAssignedEvent("pr1", "u2"),
+		domain.NewReviewerReassignedEvent(domain.Replacement{PullRequestID: "pr1", OldReviewerID: "u2", NewReviewerID: "u3"}),
+		domain.NewEvent(domain.EventPRMerged, domain.PullRequest{ID: "pr1", AuthorID: "u1", Status: domain.StatusMerged}),
+	}
+	for _, e := range events {
+		if err := sink.Deliver(ctx, e); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	want := []string{"assigned pr1 u2", "reassigned pr1 u2->u3", "merged pr1"}
+	if !reflect.DeepEqual(n.calls, want) {
+		t.Fatalf("expected notifications %v, got %v", want, n.calls)
+	}
+}
+
+func TestNotifierSink_RejectsMalformedEvent(t *testing.T) {
+	n := &recordingNotifier{}
+	e := domain.NewEvent(domain.EventReviewerAssigned, "not an assignment")
+
+	if err := NewNotifierSink(n).Deliver(context.Background(), e); err == nil {
+		t.Fatalf("expected decode error")
+	}
+	if len(n.calls) != 0 {
+		t.Fatalf("expected no notifications, got %v", n.calls)
+	}
+}
@@ -3,9 +3,10 @@ package usecase
 import (
 	"context"
 	"errors"
-	"math/rand"
+	"strings"
 	"time"
 
+	"github.com/you/pr-assign-avito/internal/codeowners"
 	"github.com/you/pr-assign-avito/internal/domain"
 	"github.com/you/pr-assign-avito/internal/repository"
 )
@@ -14,22 +15,63 @@ var (
 	ErrPRExists    = errors.New("pr exists")
 	ErrNotFound    = errors.New("not found")
 	ErrPRMerged    = errors.New("pr merged")
+	ErrPRClosed    = errors.New("pr closed")
 	ErrNotAssigned = errors.New("not assigned")
 	ErrNoCandidate = errors.New("no candidate")
 	ErrValidation  = errors.New("validation error")
+
+	ErrNotEnoughReviewers = errors.New("not enough reviewers")
+	ErrNotTeamMember      = errors.New("not team member")
+	ErrInvalidTransition  = errors.New("invalid status transition")
+	ErrMergeBlocked       = errors.New("merge blocked")
 )
 
+// MergeBlockedError - merge запрещён политикой команды; Unmet перечисляет невыполненные условия
+type MergeBlockedError struct {
+	Unmet []string
+}
+
+func (e *MergeBlockedError) Error() string {
+	return ErrMergeBlocked.Error() + ": " + strings.Join(e.Unmet, "; ")
+}
+
+func (e *MergeBlockedError) Is(target error) bool {
+	return target == ErrMergeBlocked
+}
+
 type PRUsecase struct {
-	Repo repository.Repo
-	rand *rand.Rand
+	Repo            repository.Repo
+	selectors       map[string]ReviewerSelector
+	defaultStrategy string
+}
+
+type Option func(*PRUsecase)
+
+// WithDefaultStrategy задаёт стратегию для команд без собственной настройки.
+func WithDefaultStrategy(name string) Option {
+	return func(u *PRUsecase) {
+		if domain.IsValidReviewerStrategy(name) {
+			u.defaultStrategy = name
+		}
+	}
 }
 
-func NewPRUsecase(r repository.Repo) *PRUsecase {
-	//nolint:gosec // math/rand is sufficient for non-cryptographic shuffling
-	return &PRUsecase{
+func NewPRUsecase(r repository.Repo, opts ...Option) *PRUsecase {
+	rnd := newLockedRand(time.Now().UnixNano())
+	u := &PRUsecase{
 		Repo: r,
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		selectors: map[string]ReviewerSelector{
+			domain.StrategyRandom:         &randomSelector{rand: rnd},
+			domain.StrategyRoundRobin:     &roundRobinSelector{lastID: map[int]string{}},
+			domain.StrategyLeastLoaded:    &leastLoadedSelector{rand: rnd},
+			domain.StrategyWeightedRandom: &weightedRandomSelector{rand: rnd},
+		},
+		defaultStrategy: domain.StrategyRandom,
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return u
 }
 
 func (u *PRUsecase) CreatePR(ctx context.Context, pr domain.PullRequest) (domain.PullRequest, error) {
@@ -46,29 +88,49 @@ func (u *PRUsecase) CreatePR(ctx context.Context, pr domain.PullRequest) (domain
 		return domain.PullRequest{}, ErrNotFound
 	}
 
-	candidates, err := u.Repo.GetActiveTeamMembersExcluding(ctx, author.TeamID, []string{author.ID})
+	team, err := u.Repo.GetTeamByID(ctx, author.TeamID)
 	if err != nil {
 		return domain.PullRequest{}, err
 	}
-
-	ids := make([]string, 0, len(candidates))
-	for _, c := range candidates {
-		ids = append(ids, c.ID)
+	minReviewers, maxReviewers := team.ReviewerLimits()
+	chosen, sources := []string{}, map[string]string{}
+	if len(pr.ChangedFiles) > 0 && team.Codeowners != "" {
+		chosen, sources, err = u.pickCodeOwners(ctx, team, pr.ChangedFiles, author.ID, pr.Tags, maxReviewers)
+		if err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+	rest, restSources, err := u.pickReviewers(ctx, team, append([]string{author.ID}, chosen...), pr.Tags, maxReviewers-len(chosen))
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	chosen = append(chosen, rest...)
+	for id, teamName := range restSources {
+		sources[id] = teamName
+	}
+	if len(chosen) < minReviewers {
+		return domain.PullRequest{}, ErrNotEnoughReviewers
 	}
-	u.shuffle(ids)
-	chosen := pickUpTo(ids, 2)
 
 	pr.Reviewers = chosen
-	pr.Status = "OPEN"
+	pr.ReviewerTeams = sources
+	if pr.Status != domain.StatusDraft {
+		pr.Status = domain.StatusOpen
+	}
 	pr.CreatedAt = time.Now().UTC()
 
-	if err := u.Repo.CreatePR(ctx, pr, "OPEN"); err != nil {
+	if err := u.Repo.CreatePR(ctx, pr, string(pr.Status)); err != nil {
 		return domain.PullRequest{}, err
 	}
 	return pr, nil
 }
 
 func (u *PRUsecase) ReassignReviewer(ctx context.Context, prID, oldUserID string) (string, error) {
+	return u.reassignReviewer(ctx, prID, oldUserID, domain.ReasonManualReassign)
+}
+
+// reassignReviewer заменяет oldUserID на PR, записывая в журнал назначений причину reason.
+func (u *PRUsecase) reassignReviewer(ctx context.Context, prID, oldUserID string, reason domain.AssignmentReason) (string, error) {
 	// Сначала проверяем статус PR - это главная проверка
 	pr, err := u.Repo.GetPR(ctx, prID)
 	if err != nil {
@@ -78,9 +140,9 @@ func (u *PRUsecase) ReassignReviewer(ctx context.Context, prID, oldUserID string
 		return "", err
 	}
 
-	// Проверяем, что PR не закрыт (merged)
-	if pr.Status != "OPEN" {
-		return "", ErrPRMerged
+	// Проверяем, что ревьюверов PR ещё можно менять
+	if err := reviewersLockedErr(pr.Status); err != nil {
+		return "", err
 	}
 
 	// Теперь проверяем, что ревьювер назначен
@@ -119,26 +181,27 @@ func (u *PRUsecase) ReassignReviewer(ctx context.Context, prID, oldUserID string
 		excludeList = append(excludeList, k)
 	}
 
-	cands, err := u.Repo.GetActiveTeamMembersExcluding(ctx, oldUser.TeamID, excludeList)
+	team, err := u.Repo.GetTeamByID(ctx, oldUser.TeamID)
 	if err != nil {
 		return "", err
 	}
-	if len(cands) == 0 {
-		return "", ErrNoCandidate
+	picked, _, err := u.pickReviewers(ctx, team, excludeList, pr.Tags, 1)
+	if err != nil {
+		return "", err
 	}
-	ids := []string{}
-	for _, c := range cands {
-		ids = append(ids, c.ID)
+	if len(picked) == 0 {
+		return "", ErrNoCandidate
 	}
-	u.shuffle(ids)
-	newID := ids[0]
+	newID := picked[0]
 
-	if err := u.Repo.ReplacePRReviewer(ctx, prID, oldUserID, newID); err != nil {
+	if err := u.Repo.ReplacePRReviewer(ctx, prID, oldUserID, newID, reason); err != nil {
 		switch err {
 		case repository.ErrNotFound:
 			return "", ErrNotFound
 		case repository.ErrPRMerged:
 			return "", ErrPRMerged
+		case repository.ErrPRClosed:
+			return "", ErrPRClosed
 		case repository.ErrNotAssigned:
 			return "", ErrNotAssigned
 		default:
@@ -148,13 +211,268 @@ func (u *PRUsecase) ReassignReviewer(ctx context.Context, prID, oldUserID string
 	return newID, nil
 }
 
-func (u *PRUsecase) MergePR(ctx context.Context, prID string) (domain.PullRequest, error) {
-	if err := u.Repo.MergePR(ctx, prID); err != nil {
+// ReassignOpenReviews переназначает все открытые PR, где userID назначен ревьювером.
+// PR, которые переназначить не удалось, попадают в отчёт, а не в ошибку.
+func (u *PRUsecase) ReassignOpenReviews(ctx context.Context, userID string) (domain.ReassignReport, error) {
+	report := domain.NewReassignReport()
+	prs, err := u.Repo.GetUserReviews(ctx, userID)
+	if err != nil {
+		return report, err
+	}
+	for _, pr := range prs {
+		if !pr.Status.CanChangeReviewers() {
+			continue
+		}
+		newID, err := u.reassignReviewer(ctx, pr.ID, userID, domain.ReasonReviewerUnavailable)
+		if err != nil {
+			report.Failed = append(report.Failed, domain.ReassignFailure{
+				PullRequestID: pr.ID,
+				ReviewerID:    userID,
+				Reason:        err.Error(),
+			})
+			continue
+		}
+		report.Reassigned = append(report.Reassigned, domain.Replacement{
+			PullRequestID: pr.ID,
+			OldReviewerID: userID,
+			NewReviewerID: newID,
+		})
+	}
+	return report, nil
+}
+
+// ReassignStartedAbsences переназначает открытые ревью пользователей, чьё отсутствие
+// с флагом reassign_open_reviews уже началось, и отмечает такие отсутствия обработанными.
+func (u *PRUsecase) ReassignStartedAbsences(ctx context.Context, now time.Time) (domain.ReassignReport, error) {
+	report := domain.NewReassignReport()
+	due, err := u.Repo.DueAbsenceReassignments(ctx, now)
+	if err != nil {
+		return report, err
+	}
+	for _, a := range due {
+		r, err := u.ReassignOpenReviews(ctx, a.UserID)
+		if err != nil {
+			return report, err
+		}
+		report.Reassigned = append(report.Reassigned, r.Reassigned...)
+		report.Failed = append(report.Failed, r.Failed...)
+		if err := u.Repo.MarkAbsenceReassigned(ctx, a.ID, now); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// DeactivateTeamUsers деактивирует пользователей команды teamName и передаёт их
+// открытые ревью оставшимся активным участникам той же команды. Замены
+// подбираются заранее, а деактивация и замены применяются одной транзакцией.
+func (u *PRUsecase) DeactivateTeamUsers(ctx context.Context, teamName string, userIDs []string) (domain.TeamDeactivation, error) {
+	result := domain.TeamDeactivation{TeamName: teamName, ReassignReport: domain.NewReassignReport()}
+	team, members, err := u.Repo.GetTeamByName(ctx, teamName)
+	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
+			return result, ErrNotFound
+		}
+		return result, err
+	}
+	inTeam := map[string]struct{}{}
+	for _, m := range members {
+		inTeam[m.ID] = struct{}{}
+	}
+	deactivating := map[string]struct{}{}
+	for _, id := range userIDs {
+		if _, ok := inTeam[id]; !ok {
+			return result, ErrNotTeamMember
+		}
+		if _, dup := deactivating[id]; !dup {
+			deactivating[id] = struct{}{}
+			result.DeactivatedUsers = append(result.DeactivatedUsers, id)
+		}
+	}
+
+	// текущие ревьюверы PR с учётом уже запланированных замен
+	reviewers := map[string][]string{}
+	// дополнительная нагрузка от запланированных замен
+	planned := map[string]int{}
+	for _, userID := range result.DeactivatedUsers {
+		prs, err := u.Repo.GetUserReviews(ctx, userID)
+		if err != nil {
+			return result, err
+		}
+		for _, pr := range prs {
+			if !pr.Status.CanChangeReviewers() {
+				continue
+			}
+			current, ok := reviewers[pr.ID]
+			if !ok {
+				if current, err = u.Repo.GetPRReviewers(ctx, pr.ID); err != nil {
+					return result, err
+				}
+			}
+			exclude := append([]string{pr.AuthorID}, current...)
+			exclude = append(exclude, result.DeactivatedUsers...)
+			newID, err := u.pickReplacement(ctx, team, exclude, pr.Tags, planned)
+			if err != nil {
+				return result, err
+			}
+			if newID == "" {
+				result.Failed = append(result.Failed, domain.ReassignFailure{
+					PullRequestID: pr.ID,
+					ReviewerID:    userID,
+					Reason:        ErrNoCandidate.Error(),
+				})
+				reviewers[pr.ID] = current
+				continue
+			}
+			reviewers[pr.ID] = replaceID(current, userID, newID)
+			planned[newID]++
+			result.Reassigned = append(result.Reassigned, domain.Replacement{
+				PullRequestID: pr.ID,
+				OldReviewerID: userID,
+				NewReviewerID: newID,
+			})
+		}
+	}
+
+	if err := u.Repo.DeactivateUsers(ctx, result.DeactivatedUsers, result.Reassigned); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return result, ErrNotFound
+		case errors.Is(err, repository.ErrPRMerged):
+			return result, ErrPRMerged
+		case errors.Is(err, repository.ErrPRClosed):
+			return result, ErrPRClosed
+		case errors.Is(err, repository.ErrNotAssigned):
+			return result, ErrNotAssigned
+		default:
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// pickReplacement выбирает одного ревьювера из команды team без резервных команд.
+// planned учитывает нагрузку, которая ещё не записана в хранилище.
+func (u *PRUsecase) pickReplacement(ctx context.Context, team domain.Team, exclude, tags []string, planned map[string]int) (string, error) {
+	cands, err := u.Repo.GetActiveTeamMembersExcluding(ctx, team.ID, exclude)
+	if err != nil {
+		return "", err
+	}
+	available := cands[:0]
+	for _, c := range cands {
+		c.OpenReviews += planned[c.ID]
+		if c.HasCapacity() {
+			available = append(available, c)
+		}
+	}
+	picked, err := u.selectPreferringTags(ctx, team, available, tags, 1)
+	if err != nil || len(picked) == 0 {
+		return "", err
+	}
+	return picked[0], nil
+}
+
+func replaceID(ids []string, oldID, newID string) []string {
+	res := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == oldID {
+			id = newID
+		}
+		res = append(res, id)
+	}
+	return res
+}
+
+// SubmitReview записывает решение назначенного ревьювера по PR.
+func (u *PRUsecase) SubmitReview(ctx context.Context, r domain.Review) (domain.Review, error) {
+	if !domain.IsValidReviewDecision(r.Decision) {
+		return domain.Review{}, ErrValidation
+	}
+	saved, err := u.Repo.AddReview(ctx, r)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return domain.Review{}, ErrNotFound
+		case errors.Is(err, repository.ErrPRMerged):
+			return domain.Review{}, ErrPRMerged
+		case errors.Is(err, repository.ErrPRClosed):
+			return domain.Review{}, ErrPRClosed
+		case errors.Is(err, repository.ErrNotAssigned):
+			return domain.Review{}, ErrNotAssigned
+		default:
+			return domain.Review{}, err
+		}
+	}
+	return saved, nil
+}
+
+// MergePR мержит PR, если выполнена политика merge команды автора,
+// иначе возвращает *MergeBlockedError.
+func (u *PRUsecase) MergePR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return u.merge(ctx, prID, "")
+}
+
+// ForceMergePR мержит PR в обход политики merge; adminID сохраняется в PR.
+func (u *PRUsecase) ForceMergePR(ctx context.Context, prID, adminID string) (domain.PullRequest, error) {
+	if adminID == "" {
+		return domain.PullRequest{}, ErrValidation
+	}
+	return u.merge(ctx, prID, adminID)
+}
+
+func (u *PRUsecase) merge(ctx context.Context, prID, forcedBy string) (domain.PullRequest, error) {
+	unmet, err := u.Repo.MergePR(ctx, prID, forcedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
 			return domain.PullRequest{}, ErrNotFound
+		case errors.Is(err, repository.ErrInvalidTransition):
+			return domain.PullRequest{}, ErrInvalidTransition
+		default:
+			return domain.PullRequest{}, err
 		}
+	}
+	if len(unmet) > 0 {
+		return domain.PullRequest{}, &MergeBlockedError{Unmet: unmet}
+	}
+	pr, err := u.Repo.GetPR(ctx, prID)
+	if err != nil {
 		return domain.PullRequest{}, err
 	}
+	return pr, nil
+}
+
+// ClosePR отклоняет PR без merge.
+func (u *PRUsecase) ClosePR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return u.transition(ctx, prID, domain.StatusClosed)
+}
+
+// ReopenPR повторно открывает закрытый PR.
+func (u *PRUsecase) ReopenPR(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return u.transition(ctx, prID, domain.StatusReopened)
+}
+
+// MarkDraft возвращает открытый PR в черновики.
+func (u *PRUsecase) MarkDraft(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return u.transition(ctx, prID, domain.StatusDraft)
+}
+
+// MarkReady переводит черновик в OPEN.
+func (u *PRUsecase) MarkReady(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return u.transition(ctx, prID, domain.StatusOpen)
+}
+
+func (u *PRUsecase) transition(ctx context.Context, prID string, to domain.PRStatus) (domain.PullRequest, error) {
+	if err := u.Repo.TransitionPR(ctx, prID, to); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return domain.PullRequest{}, ErrNotFound
+		case errors.Is(err, repository.ErrInvalidTransition):
+			return domain.PullRequest{}, ErrInvalidTransition
+		default:
+			return domain.PullRequest{}, err
+		}
+	}
 	pr, err := u.Repo.GetPR(ctx, prID)
 	if err != nil {
 		return domain.PullRequest{}, err
@@ -162,11 +480,199 @@ func (u *PRUsecase) MergePR(ctx context.Context, prID string) (domain.PullReques
 	return pr, nil
 }
 
-func (u *PRUsecase) shuffle(ids []string) {
-	for i := range ids {
-		j := u.rand.Intn(i + 1)
-		ids[i], ids[j] = ids[j], ids[i]
+// reviewersLockedErr возвращает ошибку, если ревьюверов PR в статусе s менять нельзя
+func reviewersLockedErr(s domain.PRStatus) error {
+	switch {
+	case s == domain.StatusMerged:
+		return ErrPRMerged
+	case !s.CanChangeReviewers():
+		return ErrPRClosed
+	}
+	return nil
+}
+
+// pickReviewers выбирает до n ревьюверов из команды team, а если её кандидатов
+// не хватает - из резервных команд в порядке приоритета. Вторым значением
+// возвращается команда каждого выбранного ревьювера. Внутри каждой команды
+// предпочтение отдаётся кандидатам с экспертизой по тегам PR.
+func (u *PRUsecase) pickReviewers(ctx context.Context, team domain.Team, exclude, tags []string, n int) ([]string, map[string]string, error) {
+	chosen := []string{}
+	sources := map[string]string{}
+	exclude = append([]string{}, exclude...)
+
+	teamIDs := append([]int{team.ID}, team.FallbackTeamIDs...)
+	for i, teamID := range teamIDs {
+		if len(chosen) >= n {
+			break
+		}
+		current := team
+		if i > 0 {
+			t, err := u.Repo.GetTeamByID(ctx, teamID)
+			if err != nil {
+				return nil, nil, err
+			}
+			current = t
+		}
+		cands, err := u.Repo.GetActiveTeamMembersExcluding(ctx, current.ID, exclude)
+		if err != nil {
+			return nil, nil, err
+		}
+		picked, err := u.selectPreferringTags(ctx, current, cands, tags, n-len(chosen))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range picked {
+			chosen = append(chosen, id)
+			sources[id] = current.Name
+			exclude = append(exclude, id)
+		}
+	}
+	return chosen, sources, nil
+}
+
+// pickCodeOwners назначает по одному владельцу на каждое правило CODEOWNERS команды,
+// которому соответствует хотя бы один изменённый файл. Правило, у которого уже
+// назначен владелец или нет доступных владельцев, пропускается. Владельцев назначается
+// не больше limit, правила сверх лимита остаются без владельца.
+func (u *PRUsecase) pickCodeOwners(ctx context.Context, team domain.Team, files []string, authorID string, tags []string, limit int) ([]string, map[string]string, error) {
+	rules, err := codeowners.Parse(team.Codeowners)
+	if err != nil {
+		return nil, nil, err
+	}
+	chosen := []string{}
+	sources := map[string]string{}
+	seen := map[int]struct{}{}
+	for _, f := range files {
+		if len(chosen) >= limit {
+			break
+		}
+		rule, ok := rules.Match(f)
+		if !ok || len(rule.Owners) == 0 {
+			continue
+		}
+		if _, done := seen[rule.Line]; done {
+			continue
+		}
+		seen[rule.Line] = struct{}{}
+
+		cands, err := u.ownerCandidates(ctx, rule.Owners, authorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		satisfied := false
+		available := make([]domain.User, 0, len(cands))
+		for _, c := range cands {
+			if _, ok := sources[c.ID]; ok {
+				satisfied = true
+				break
+			}
+			available = append(available, c)
+		}
+		if satisfied {
+			continue
+		}
+		picked, err := u.selectPreferringTags(ctx, team, available, tags, 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, id := range picked {
+			for _, c := range available {
+				if c.ID == id {
+					chosen = append(chosen, id)
+					sources[id] = c.TeamName
+				}
+			}
+		}
+	}
+	return chosen, sources, nil
+}
+
+// ownerCandidates возвращает доступных ревьюверов среди владельцев правила.
+// Неизвестные пользователи и команды пропускаются.
+func (u *PRUsecase) ownerCandidates(ctx context.Context, owners []string, authorID string) ([]domain.User, error) {
+	var res []domain.User
+	added := map[string]struct{}{}
+	exclude := []string{authorID}
+	for _, o := range owners {
+		userID, teamName, ok := codeowners.ParseOwner(o)
+		if !ok {
+			continue
+		}
+		var teamID int
+		if teamName != "" {
+			t, _, err := u.Repo.GetTeamByName(ctx, teamName)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			teamID = t.ID
+		} else {
+			usr, err := u.Repo.GetUserByID(ctx, userID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					continue
+				}
+				return nil, err
+			}
+			teamID = usr.TeamID
+		}
+		cands, err := u.Repo.GetActiveTeamMembersExcluding(ctx, teamID, exclude)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cands {
+			if userID != "" && c.ID != userID {
+				continue
+			}
+			if _, dup := added[c.ID]; !dup {
+				added[c.ID] = struct{}{}
+				res = append(res, c)
+			}
+		}
+	}
+	return res, nil
+}
+
+// selectPreferringTags выбирает до n кандидатов сначала среди тех, чья экспертиза
+// пересекается с tags, и добирает недостающих из остальных.
+func (u *PRUsecase) selectPreferringTags(ctx context.Context, team domain.Team, cands []domain.User, tags []string, n int) ([]string, error) {
+	sel := u.selectorFor(team)
+	if len(tags) == 0 {
+		return sel.Select(ctx, team.ID, cands, n)
+	}
+	var matched, rest []domain.User
+	for _, c := range cands {
+		if c.HasAnyTag(tags) {
+			matched = append(matched, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	if ts, ok := sel.(tieredSelector); ok {
+		return ts.SelectTiered(ctx, team.ID, [][]domain.User{matched, rest}, n)
+	}
+	picked, err := sel.Select(ctx, team.ID, matched, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(picked) < n && len(rest) > 0 {
+		more, err := sel.Select(ctx, team.ID, rest, n-len(picked))
+		if err != nil {
+			return nil, err
+		}
+		picked = append(picked, more...)
+	}
+	return picked, nil
+}
+
+// selectorFor возвращает стратегию команды, либо стратегию по умолчанию.
+func (u *PRUsecase) selectorFor(team domain.Team) ReviewerSelector {
+	if s, ok := u.selectors[team.ReviewerStrategy]; ok {
+		return s
 	}
+	return u.selectors[u.defaultStrategy]
 }
 
 func pickUpTo(ids []string, n int) []string {
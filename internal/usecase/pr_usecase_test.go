@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,49 +13,107 @@ import (
 )
 
 type memRepo struct {
-	teams     map[string]int
+	teams     map[string]domain.Team
 	users     map[string]domain.User
 	prs       map[string]domain.PullRequest
 	reviewers map[string][]string
 	statuses  map[string]int
+	absences  map[int]domain.Absence
+	reviews   []domain.Review
+	stale     []domain.StaleReview
+	webhooks  []domain.Webhook
+	history   []domain.AssignmentEvent
+
+	accounts map[string]string
 }
 
 func newMemRepo() *memRepo {
 	m := &memRepo{
-		teams:     map[string]int{},
+		teams:     map[string]domain.Team{},
 		users:     map[string]domain.User{},
 		prs:       map[string]domain.PullRequest{},
 		reviewers: map[string][]string{},
 		statuses:  map[string]int{"OPEN": 1, "MERGED": 2},
+		absences:  map[int]domain.Absence{},
 	}
 	return m
 }
 
-func (m *memRepo) CreateTeamWithMembers(ctx context.Context, teamName string, members []domain.User) error {
-	if _, exists := m.teams[teamName]; exists {
+func (m *memRepo) CreateTeamWithMembers(ctx context.Context, team domain.Team, members []domain.TeamMember) error {
+	users := make([]domain.User, 0, len(members))
+	for _, tm := range members {
+		users = append(users, tm.ApplyTo(m.users[tm.ID]))
+	}
+	return m.addTeam(ctx, team, users)
+}
+func (m *memRepo) addTeam(ctx context.Context, team domain.Team, members []domain.User) error {
+	if _, exists := m.teams[team.Name]; exists {
 		return repository.ErrTeamExists
 	}
-	id := len(m.teams) + 1
-	m.teams[teamName] = id
+	team.ID = len(m.teams) + 1
+	if err := m.resolveFallbacks(&team); err != nil {
+		return err
+	}
+	m.teams[team.Name] = team
 	for _, u := range members {
-		u.TeamID = id
-		u.TeamName = teamName
+		u.TeamID = team.ID
+		u.TeamName = team.Name
 		m.users[u.ID] = u
 	}
 	return nil
 }
 func (m *memRepo) GetTeamByName(ctx context.Context, name string) (domain.Team, []domain.User, error) {
-	id, ok := m.teams[name]
+	team, ok := m.teams[name]
 	if !ok {
 		return domain.Team{}, nil, repository.ErrNotFound
 	}
 	var list []domain.User
 	for _, u := range m.users {
-		if u.TeamID == id {
+		if u.TeamID == team.ID {
 			list = append(list, u)
 		}
 	}
-	return domain.Team{ID: id, Name: name}, list, nil
+	return team, list, nil
+}
+func (m *memRepo) GetTeamByID(ctx context.Context, teamID int) (domain.Team, error) {
+	for _, t := range m.teams {
+		if t.ID == teamID {
+			return t, nil
+		}
+	}
+	return domain.Team{}, repository.ErrNotFound
+}
+func (m *memRepo) UpdateTeam(ctx context.Context, team domain.Team) error {
+	stored, ok := m.teams[team.Name]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	team.ID = stored.ID
+	if err := m.resolveFallbacks(&team); err != nil {
+		return err
+	}
+	m.teams[team.Name] = team
+	return nil
+}
+func (m *memRepo) resolveFallbacks(team *domain.Team) error {
+	team.FallbackTeamIDs = nil
+	for _, name := range team.FallbackTeams {
+		fb, ok := m.teams[name]
+		if !ok {
+			return repository.ErrUnknownTeam
+		}
+		team.FallbackTeamIDs = append(team.FallbackTeamIDs, fb.ID)
+	}
+	return nil
+}
+func (m *memRepo) SetTeamCodeowners(ctx context.Context, teamName, doc string) error {
+	team, ok := m.teams[teamName]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	team.Codeowners = doc
+	m.teams[teamName] = team
+	return nil
 }
 func (m *memRepo) SetUserActive(ctx context.Context, userID string, active bool) (domain.User, error) {
 	u, ok := m.users[userID]
@@ -64,6 +125,76 @@ func (m *memRepo) SetUserActive(ctx context.Context, userID string, active bool)
 	u.TeamName = m.teamNameByID(u.TeamID)
 	return u, nil
 }
+func (m *memRepo) DeactivateUsers(ctx context.Context, userIDs []string, replacements []domain.Replacement) error {
+	for _, id := range userIDs {
+		if _, ok := m.users[id]; !ok {
+			return repository.ErrNotFound
+		}
+	}
+	for _, id := range userIDs {
+		u := m.users[id]
+		u.IsActive = false
+		m.users[id] = u
+	}
+	for _, r := range replacements {
+		if err := m.ReplacePRReviewer(ctx, r.PullRequestID, r.OldReviewerID, r.NewReviewerID, domain.ReasonTeamDeactivation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *memRepo) SetUserMaxReviews(ctx context.Context, userID string, maxReviews int) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.MaxConcurrentReviews = maxReviews
+	m.users[userID] = u
+	return u, nil
+}
+func (m *memRepo) SetUserTags(ctx context.Context, userID string, tags []string) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.Tags = tags
+	m.users[userID] = u
+	return u, nil
+}
+func (m *memRepo) SetUserChatHandle(ctx context.Context, userID, handle string) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.ChatHandle = handle
+	m.users[userID] = u
+	return u, nil
+}
+func (m *memRepo) SetUserDigest(ctx context.Context, userID, email string, optOut bool) (domain.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return domain.User{}, repository.ErrNotFound
+	}
+	u.Email, u.DigestOptOut = email, optOut
+	m.users[userID] = u
+	return u, nil
+}
+func (m *memRepo) GetPRHistory(ctx context.Context, prID string) ([]domain.AssignmentEvent, error) {
+	var events []domain.AssignmentEvent
+	for _, e := range m.history {
+		if e.PullRequestID == prID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+func (m *memRepo) GetPRAt(ctx context.Context, prID string, at time.Time) (domain.PullRequest, error) {
+	pr, ok := m.prs[prID]
+	if !ok || pr.CreatedAt.After(at) {
+		return domain.PullRequest{}, repository.ErrNotFound
+	}
+	return pr, nil
+}
 func (m *memRepo) GetUserByID(ctx context.Context, userID string) (domain.User, error) {
 	u, ok := m.users[userID]
 	if !ok {
@@ -86,9 +217,11 @@ func (m *memRepo) GetActiveTeamMembersExcluding(ctx context.Context, teamID int,
 	for _, e := range exclude {
 		set[e] = struct{}{}
 	}
+	counts := m.openReviewCounts()
 	var res []domain.User
 	for _, u := range m.users {
-		if u.TeamID == teamID && u.IsActive {
+		u.OpenReviews = counts[u.ID]
+		if u.TeamID == teamID && u.IsActive && u.HasCapacity() && !m.isAbsent(u.ID, time.Now()) {
 			if _, ex := set[u.ID]; !ex {
 				res = append(res, u)
 			}
@@ -96,17 +229,47 @@ func (m *memRepo) GetActiveTeamMembersExcluding(ctx context.Context, teamID int,
 	}
 	return res, nil
 }
+func (m *memRepo) AddReview(ctx context.Context, r domain.Review) (domain.Review, error) {
+	pr, ok := m.prs[r.PullRequestID]
+	if !ok {
+		return domain.Review{}, repository.ErrNotFound
+	}
+	if pr.Status == domain.StatusMerged {
+		return domain.Review{}, repository.ErrPRMerged
+	}
+	if !pr.Status.CanChangeReviewers() {
+		return domain.Review{}, repository.ErrPRClosed
+	}
+	if assigned, _ := m.IsReviewerAssigned(ctx, r.PullRequestID, r.ReviewerID); !assigned {
+		return domain.Review{}, repository.ErrNotAssigned
+	}
+	r.ID = len(m.reviews) + 1
+	r.CreatedAt = time.Now().UTC()
+	m.reviews = append(m.reviews, r)
+	return r, nil
+}
+func (m *memRepo) latestReview(prID, reviewerID string) (domain.Review, bool) {
+	for i := len(m.reviews) - 1; i >= 0; i-- {
+		if r := m.reviews[i]; r.PullRequestID == prID && r.ReviewerID == reviewerID {
+			return r, true
+		}
+	}
+	return domain.Review{}, false
+}
 func (m *memRepo) GetPRReviewers(ctx context.Context, prID string) ([]string, error) {
 	return m.reviewers[prID], nil
 }
-func (m *memRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
+func (m *memRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUserID string, reason domain.AssignmentReason) error {
 	pr, ok := m.prs[prID]
 	if !ok {
 		return repository.ErrNotFound
 	}
-	if pr.Status == "MERGED" {
+	if pr.Status == domain.StatusMerged {
 		return repository.ErrPRMerged
 	}
+	if !pr.Status.CanChangeReviewers() {
+		return repository.ErrPRClosed
+	}
 	arr := m.reviewers[prID]
 	found := false
 	for i, v := range arr {
@@ -122,27 +285,69 @@ func (m *memRepo) ReplacePRReviewer(ctx context.Context, prID, oldUserID, newUse
 	m.reviewers[prID] = arr
 	pr.Reviewers = append([]string{}, arr...)
 	m.prs[prID] = pr
+	m.history = append(m.history, domain.AssignmentEvent{
+		PullRequestID: prID, Actor: domain.ActorFrom(ctx), OldReviewerID: oldUserID, NewReviewerID: newUserID, Reason: reason,
+	})
 	return nil
 }
-func (m *memRepo) MergePR(ctx context.Context, prID string) error {
+func (m *memRepo) TransitionPR(ctx context.Context, prID string, to domain.PRStatus) error {
 	pr, ok := m.prs[prID]
 	if !ok {
 		return repository.ErrNotFound
 	}
-	if pr.Status == "MERGED" {
+	if pr.Status == to {
 		return nil
 	}
-	pr.Status = "MERGED"
+	if !domain.CanTransition(pr.Status, to) {
+		return repository.ErrInvalidTransition
+	}
+	pr.Status = to
+	if to == domain.StatusMerged {
+		t := time.Now().UTC()
+		pr.MergedAt = &t
+	}
+	m.prs[prID] = pr
+	return nil
+}
+func (m *memRepo) MergePR(ctx context.Context, prID, forcedBy string) ([]string, error) {
+	pr, ok := m.prs[prID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if pr.Status == domain.StatusMerged {
+		return nil, nil
+	}
+	if !domain.CanTransition(pr.Status, domain.StatusMerged) {
+		return nil, repository.ErrInvalidTransition
+	}
+	if forcedBy == "" {
+		team, _ := m.GetTeamByID(ctx, m.users[pr.AuthorID].TeamID)
+		current, _ := m.GetPR(ctx, prID)
+		if unmet := team.MergeBlockers(current.ReviewDecisions); len(unmet) > 0 {
+			return unmet, nil
+		}
+	}
 	t := time.Now().UTC()
+	pr.Status = domain.StatusMerged
 	pr.MergedAt = &t
+	pr.MergeForcedBy = forcedBy
 	m.prs[prID] = pr
-	return nil
+	return nil, nil
 }
 func (m *memRepo) GetPR(ctx context.Context, prID string) (domain.PullRequest, error) {
 	pr, ok := m.prs[prID]
 	if !ok {
 		return domain.PullRequest{}, repository.ErrNotFound
 	}
+	pr.ReviewDecisions = nil
+	for _, r := range m.reviewers[prID] {
+		if review, ok := m.latestReview(prID, r); ok {
+			if pr.ReviewDecisions == nil {
+				pr.ReviewDecisions = map[string]domain.Review{}
+			}
+			pr.ReviewDecisions[r] = review
+		}
+	}
 	return pr, nil
 }
 func (m *memRepo) IsReviewerAssigned(ctx context.Context, prID, userID string) (bool, error) {
@@ -164,7 +369,7 @@ func (m *memRepo) HasOpenPRsAsReviewer(ctx context.Context, userID string) (bool
 	for prID, revs := range m.reviewers {
 		for _, r := range revs {
 			if r == userID {
-				if pr, ok := m.prs[prID]; ok && pr.Status == "OPEN" {
+				if pr, ok := m.prs[prID]; ok && pr.Status.IsOpen() {
 					return true, nil
 				}
 			}
@@ -177,7 +382,7 @@ func (m *memRepo) LockPRForUpdate(ctx context.Context, prID string) (string, err
 	if !ok {
 		return "", repository.ErrNotFound
 	}
-	return pr.Status, nil
+	return string(pr.Status), nil
 }
 func (m *memRepo) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error) {
 	var res []domain.PullRequest
@@ -185,6 +390,9 @@ func (m *memRepo) GetUserReviews(ctx context.Context, userID string) ([]domain.P
 		for _, r := range revs {
 			if r == userID {
 				if pr, ok := m.prs[prID]; ok {
+					if review, ok := m.latestReview(prID, userID); ok {
+						pr.ReviewDecisions = map[string]domain.Review{userID: review}
+					}
 					res = append(res, pr)
 				}
 				break
@@ -195,14 +403,81 @@ func (m *memRepo) GetUserReviews(ctx context.Context, userID string) ([]domain.P
 }
 
 func (m *memRepo) teamNameByID(id int) string {
-	for name, storedID := range m.teams {
-		if storedID == id {
+	for name, t := range m.teams {
+		if t.ID == id {
 			return name
 		}
 	}
 	return ""
 }
 
+func (m *memRepo) GetOverduePRs(ctx context.Context, now time.Time) ([]domain.OverduePR, error) {
+	return nil, nil
+}
+func (m *memRepo) GetStaleReviews(ctx context.Context, now time.Time) ([]domain.StaleReview, error) {
+	return m.stale, nil
+}
+func (m *memRepo) AddEscalationReviewer(ctx context.Context, prID, staleReviewerID, newReviewerID string) error {
+	pr, ok := m.prs[prID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if !pr.Status.CanChangeReviewers() {
+		return repository.ErrPRClosed
+	}
+	if assigned, _ := m.IsReviewerAssigned(ctx, prID, staleReviewerID); !assigned {
+		return repository.ErrNotAssigned
+	}
+	m.reviewers[prID] = append(m.reviewers[prID], newReviewerID)
+	return nil
+}
+func (m *memRepo) AddWebhook(ctx context.Context, w domain.Webhook) (domain.Webhook, error) {
+	w.ID = len(m.webhooks) + 1
+	w.CreatedAt = time.Now().UTC()
+	m.webhooks = append(m.webhooks, w)
+	return w, nil
+}
+func (m *memRepo) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return m.webhooks, nil
+}
+func (m *memRepo) DeleteWebhook(ctx context.Context, webhookID int) error {
+	for i, w := range m.webhooks {
+		if w.ID == webhookID {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+func (m *memRepo) LinkExternalAccount(ctx context.Context, a domain.ExternalAccount) (domain.ExternalAccount, error) {
+	if m.accounts == nil {
+		m.accounts = map[string]string{}
+	}
+	m.accounts[a.Provider+"/"+a.Login] = a.UserID
+	return a, nil
+}
+func (m *memRepo) UnlinkExternalAccount(ctx context.Context, provider, login string) error {
+	if _, ok := m.accounts[provider+"/"+login]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.accounts, provider+"/"+login)
+	return nil
+}
+func (m *memRepo) GetUserIDByExternalLogin(ctx context.Context, provider, login string) (string, error) {
+	id, ok := m.accounts[provider+"/"+login]
+	if !ok {
+		return "", repository.ErrNotFound
+	}
+	return id, nil
+}
+func (m *memRepo) GetExternalLogin(ctx context.Context, provider, userID string) (string, error) {
+	for key, id := range m.accounts {
+		if id == userID && strings.HasPrefix(key, provider+"/") {
+			return strings.TrimPrefix(key, provider+"/"), nil
+		}
+	}
+	return "", repository.ErrNotFound
+}
 func (m *memRepo) GetReviewerStats(ctx context.Context) ([]repository.ReviewerStat, error) {
 	userCounts := make(map[string]int)
 	for _, reviewers := range m.reviewers {
@@ -233,10 +508,80 @@ func (m *memRepo) GetReviewerStats(ctx context.Context) ([]repository.ReviewerSt
 	return stats, nil
 }
 
+func (m *memRepo) openReviewCounts() map[string]int {
+	counts := map[string]int{}
+	for prID, revs := range m.reviewers {
+		if pr, ok := m.prs[prID]; !ok || !pr.Status.IsOpen() {
+			continue
+		}
+		for _, r := range revs {
+			counts[r]++
+		}
+	}
+	return counts
+}
+
+func (m *memRepo) AddAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	a.ID = len(m.absences) + 1
+	m.absences[a.ID] = a
+	return a, nil
+}
+func (m *memRepo) GetUserAbsences(ctx context.Context, userID string) ([]domain.Absence, error) {
+	var res []domain.Absence
+	for _, a := range m.absences {
+		if a.UserID == userID {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+func (m *memRepo) UpdateAbsence(ctx context.Context, a domain.Absence) (domain.Absence, error) {
+	stored, ok := m.absences[a.ID]
+	if !ok {
+		return domain.Absence{}, repository.ErrNotFound
+	}
+	a.UserID = stored.UserID
+	if a.StartsAt.Equal(stored.StartsAt) {
+		a.ReassignedAt = stored.ReassignedAt
+	}
+	m.absences[a.ID] = a
+	return a, nil
+}
+func (m *memRepo) DeleteAbsence(ctx context.Context, absenceID int) error {
+	if _, ok := m.absences[absenceID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.absences, absenceID)
+	return nil
+}
+func (m *memRepo) DueAbsenceReassignments(ctx context.Context, now time.Time) ([]domain.Absence, error) {
+	var res []domain.Absence
+	for _, a := range m.absences {
+		if a.ReassignOpenReviews && a.ReassignedAt == nil && a.Covers(now) {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+func (m *memRepo) MarkAbsenceReassigned(ctx context.Context, absenceID int, at time.Time) error {
+	a := m.absences[absenceID]
+	a.ReassignedAt = &at
+	m.absences[absenceID] = a
+	return nil
+}
+func (m *memRepo) isAbsent(userID string, at time.Time) bool {
+	for _, a := range m.absences {
+		if a.UserID == userID && a.Covers(at) {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper функции для тестов
 func setupTeamWithUsers(repo *memRepo, teamName string, users []domain.User) error {
 	ctx := context.Background()
-	return repo.CreateTeamWithMembers(ctx, teamName, users)
+	return repo.addTeam(ctx, domain.Team{Name: teamName}, users)
 }
 
 func setupPRWithReviewers(repo *memRepo, pr domain.PullRequest, reviewers []string) {
@@ -297,7 +642,7 @@ func TestReassign_NoCandidate(t *testing.T) {
 func TestMerge_Idempotent(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 	}); err != nil {
 		t.Fatalf("failed to create team: %v", err)
@@ -323,7 +668,7 @@ func TestMerge_Idempotent(t *testing.T) {
 func TestCreatePR_PRExists(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 	}); err != nil {
@@ -365,7 +710,7 @@ func TestCreatePR_AuthorNotFound(t *testing.T) {
 func TestCreatePR_NoCandidates(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 	}); err != nil {
 		t.Fatalf("failed to create team: %v", err)
@@ -384,7 +729,7 @@ func TestCreatePR_NoCandidates(t *testing.T) {
 func TestCreatePR_OneCandidate(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 	}); err != nil {
@@ -407,7 +752,7 @@ func TestCreatePR_OneCandidate(t *testing.T) {
 func TestCreatePR_MultipleCandidates(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 		{ID: "u3", Username: "carl", TeamID: 1, IsActive: true},
@@ -435,7 +780,7 @@ func TestCreatePR_MultipleCandidates(t *testing.T) {
 func TestCreatePR_ExcludesInactive(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: false},
 		{ID: "u3", Username: "carl", TeamID: 1, IsActive: true},
@@ -460,7 +805,7 @@ func TestCreatePR_ExcludesInactive(t *testing.T) {
 func TestReassignReviewer_Success(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 		{ID: "u3", Username: "carl", TeamID: 1, IsActive: true},
@@ -497,7 +842,7 @@ func TestReassignReviewer_Success(t *testing.T) {
 func TestReassignReviewer_PRNotFound(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 	}); err != nil {
 		t.Fatalf("failed to create team: %v", err)
@@ -531,7 +876,7 @@ func TestReassignReviewer_PRAlreadyMerged(t *testing.T) {
 func TestReassignReviewer_NotAssigned(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 		{ID: "u3", Username: "carl", TeamID: 1, IsActive: true},
@@ -571,7 +916,7 @@ func TestReassignReviewer_OldUserNotFound(t *testing.T) {
 func TestReassignReviewer_OldUserDoesNotExist(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 		{ID: "u3", Username: "carl", TeamID: 1, IsActive: true},
@@ -611,7 +956,7 @@ func TestMergePR_NotFound(t *testing.T) {
 func TestMergePR_Success(t *testing.T) {
 	ctx := context.Background()
 	repo := newMemRepo()
-	if err := repo.CreateTeamWithMembers(ctx, "backend", []domain.User{
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend"}, []domain.User{
 		{ID: "u1", Username: "alice", TeamID: 1, IsActive: true},
 		{ID: "u2", Username: "bob", TeamID: 1, IsActive: true},
 	}); err != nil {
@@ -662,3 +1007,617 @@ func TestPickUpTo(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePR_TeamMaxReviewers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", MaxReviewers: 3}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true},
+		{ID: "u5", Username: "eve", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 3 {
+		t.Fatalf("expected 3 reviewers, got %d", len(created.Reviewers))
+	}
+}
+
+func TestCreatePR_NotEnoughReviewers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", MinReviewers: 2, MaxReviewers: 2}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: false},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	_, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1"})
+	assertError(t, err, ErrNotEnoughReviewers, "expected ErrNotEnoughReviewers")
+	if exists, _ := repo.PRExists(ctx, "pr1"); exists {
+		t.Fatalf("PR should not be created when minimum is not met")
+	}
+}
+
+func TestCreatePR_SkipsReviewersAtCapacity(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true, MaxConcurrentReviews: 1},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "old", AuthorID: "u3", Status: "OPEN"}, []string{"u2"})
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 1 || created.Reviewers[0] != "u3" {
+		t.Fatalf("expected only u3 to be assigned, got %v", created.Reviewers)
+	}
+}
+
+func TestCreatePR_FallbackTeam(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "platform", []domain.User{
+		{ID: "p1", Username: "pat", IsActive: true},
+		{ID: "p2", Username: "paula", IsActive: false},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", FallbackTeams: []string{"platform"}}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 2 || created.Reviewers[0] != "u2" || created.Reviewers[1] != "p1" {
+		t.Fatalf("expected [u2 p1], got %v", created.Reviewers)
+	}
+	if created.ReviewerTeams["u2"] != "backend" || created.ReviewerTeams["p1"] != "platform" {
+		t.Fatalf("unexpected reviewer teams %v", created.ReviewerTeams)
+	}
+}
+
+func TestReassignReviewer_FallbackTeam(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "platform", []domain.User{
+		{ID: "p1", Username: "pat", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", FallbackTeams: []string{"platform"}}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", Title: "fix", AuthorID: "u1", Status: "OPEN"}, []string{"u2"})
+	u := NewPRUsecase(repo)
+	newID, err := u.ReassignReviewer(ctx, "pr1", "u2")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if newID != "p1" {
+		t.Fatalf("expected fallback reviewer p1, got %s", newID)
+	}
+}
+
+func TestCreatePR_SkipsAbsentReviewers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	now := time.Now().UTC()
+	// u2 в отпуске сейчас, отпуск u3 ещё не начался
+	_, _ = repo.AddAbsence(ctx, domain.Absence{UserID: "u2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(24 * time.Hour)})
+	_, _ = repo.AddAbsence(ctx, domain.Absence{UserID: "u3", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour)})
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", created.Reviewers)
+	}
+	for _, r := range created.Reviewers {
+		if r == "u2" {
+			t.Fatalf("absent user must not be assigned")
+		}
+	}
+}
+
+func TestReassignOpenReviews_Report(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: false},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", AuthorID: "u1", Status: "OPEN"}, []string{"u2"})
+	// единственный свободный кандидат уже назначен вторым ревьювером
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr2", AuthorID: "u1", Status: "OPEN"}, []string{"u2", "u3"})
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr3", AuthorID: "u1", Status: "MERGED"}, []string{"u2"})
+	u := NewPRUsecase(repo)
+	report, err := u.ReassignOpenReviews(ctx, "u2")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(report.Reassigned) != 1 || report.Reassigned[0].PullRequestID != "pr1" || report.Reassigned[0].NewReviewerID != "u3" {
+		t.Fatalf("expected pr1 reassigned to u3, got %+v", report.Reassigned)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].PullRequestID != "pr2" {
+		t.Fatalf("expected pr2 to fail, got %+v", report.Failed)
+	}
+	if revs := repo.reviewers["pr3"]; len(revs) != 1 || revs[0] != "u2" {
+		t.Fatalf("merged PR must not be touched, got %v", revs)
+	}
+}
+
+func TestReassignStartedAbsences(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", AuthorID: "u1", Status: "OPEN"}, []string{"u2"})
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr2", AuthorID: "u1", Status: "OPEN"}, []string{"u3"})
+	now := time.Now().UTC()
+	// отпуск u2 уже начался, отпуск u3 ещё впереди
+	started, _ := repo.AddAbsence(ctx, domain.Absence{UserID: "u2", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(24 * time.Hour), ReassignOpenReviews: true})
+	planned, _ := repo.AddAbsence(ctx, domain.Absence{UserID: "u3", StartsAt: now.Add(24 * time.Hour), EndsAt: now.Add(48 * time.Hour), ReassignOpenReviews: true})
+	u := NewPRUsecase(repo)
+
+	report, err := u.ReassignStartedAbsences(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(report.Reassigned) != 1 || report.Reassigned[0].PullRequestID != "pr1" || report.Reassigned[0].OldReviewerID != "u2" {
+		t.Fatalf("expected u2 replaced on pr1, got %+v", report)
+	}
+	if revs := repo.reviewers["pr2"]; len(revs) != 1 || revs[0] != "u3" {
+		t.Fatalf("planned absence must not reassign yet, got %v", revs)
+	}
+	if repo.absences[started.ID].ReassignedAt == nil || repo.absences[planned.ID].ReassignedAt != nil {
+		t.Fatalf("expected only the started absence to be marked, got %+v", repo.absences)
+	}
+	again, err := u.ReassignStartedAbsences(ctx, now)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(again.Reassigned) != 0 || len(again.Failed) != 0 {
+		t.Fatalf("expected handled absence to be skipped, got %+v", again)
+	}
+}
+
+func TestReassignReviewer_RecordsHistory(t *testing.T) {
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", AuthorID: "u1", Status: "OPEN"}, []string{"u2"})
+	u := NewPRUsecase(repo)
+
+	if _, err := u.ReassignReviewer(domain.WithActor(context.Background(), "alice"), "pr1", "u2"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, err := u.ReassignOpenReviews(context.Background(), "u3"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	history, _ := repo.GetPRHistory(context.Background(), "pr1")
+	want := []domain.AssignmentEvent{
+		{PullRequestID: "pr1", Actor: "alice", OldReviewerID: "u2", NewReviewerID: "u3", Reason: domain.ReasonManualReassign},
+		{PullRequestID: "pr1", Actor: domain.ActorSystem, OldReviewerID: "u3", NewReviewerID: "u2", Reason: domain.ReasonReviewerUnavailable},
+	}
+	if !reflect.DeepEqual(history, want) {
+		t.Fatalf("expected history %+v, got %+v", want, history)
+	}
+}
+
+func TestDeactivateTeamUsers_ReassignsToRemainingMembers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true, MaxConcurrentReviews: 1},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", AuthorID: "u1", Status: "OPEN"}, []string{"u2", "u3"})
+	// после замены на pr1 у u4 исчерпан лимит, других кандидатов нет
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr2", AuthorID: "u1", Status: "OPEN"}, []string{"u3"})
+	u := NewPRUsecase(repo)
+	result, err := u.DeactivateTeamUsers(ctx, "backend", []string{"u2", "u3"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(result.DeactivatedUsers) != 2 || repo.users["u2"].IsActive || repo.users["u3"].IsActive {
+		t.Fatalf("expected u2 and u3 to be deactivated, got %+v", result)
+	}
+	for _, r := range result.Reassigned {
+		if r.NewReviewerID == "u2" || r.NewReviewerID == "u3" {
+			t.Fatalf("deactivated user chosen as replacement: %+v", r)
+		}
+	}
+	if got := repo.reviewers["pr1"]; len(got) != 2 || got[0] != "u4" || got[1] != "u3" {
+		t.Fatalf("expected pr1 reviewers [u4 u3], got %v", got)
+	}
+	if len(result.Failed) != 2 {
+		t.Fatalf("expected 2 failures (second pr1 slot and pr2), got %+v", result.Failed)
+	}
+}
+
+func TestDeactivateTeamUsers_NotTeamMember(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	if err := setupTeamWithUsers(repo, "frontend", []domain.User{
+		{ID: "f1", Username: "fiona", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	_, err := u.DeactivateTeamUsers(ctx, "backend", []string{"u1", "f1"})
+	assertError(t, err, ErrNotTeamMember, "expected ErrNotTeamMember")
+	if !repo.users["u1"].IsActive {
+		t.Fatalf("no user should be deactivated on validation error")
+	}
+}
+
+func TestCreatePR_PrefersTaggedReviewers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true, Tags: []string{"frontend"}},
+		{ID: "u3", Username: "carl", IsActive: true, Tags: []string{"db", "backend"}},
+		{ID: "u4", Username: "dave", IsActive: true},
+		{ID: "u5", Username: "eve", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Tags: []string{"db"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// u3 - единственный с экспертизой по db, второй ревьювер добирается из общего пула
+	if len(created.Reviewers) != 2 || created.Reviewers[0] != "u3" {
+		t.Fatalf("expected u3 first among 2 reviewers, got %v", created.Reviewers)
+	}
+}
+
+func TestCreatePR_NoTagMatchFallsBack(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true, Tags: []string{"frontend"}},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Tags: []string{"db"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers from the normal pool, got %v", created.Reviewers)
+	}
+}
+
+func TestCreatePR_CodeOwnersRespectMaxReviewers(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", MaxReviewers: 2, Codeowners: `
+*.sql  @u2
+*.go   @u3
+*.md   @u4
+`}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{
+		ID:           "pr1",
+		Title:        "feat",
+		AuthorID:     "u1",
+		ChangedFiles: []string{"db/0001.sql", "main.go", "README.md"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 2 || created.Reviewers[0] != "u2" || created.Reviewers[1] != "u3" {
+		t.Fatalf("expected owners u2 and u3 capped at max_reviewers, got %v", created.Reviewers)
+	}
+}
+
+func TestCreatePR_AssignsCodeOwners(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "docs", []domain.User{
+		{ID: "d1", Username: "dora", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", MaxReviewers: 3, Codeowners: `
+*.sql    @u4
+/docs/   @org/docs
+`}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{
+		ID:           "pr1",
+		Title:        "feat",
+		AuthorID:     "u1",
+		ChangedFiles: []string{"db/0001.sql", "db/0002.sql", "docs/api.md", "main.go"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 3 || created.Reviewers[0] != "u4" || created.Reviewers[1] != "d1" {
+		t.Fatalf("expected owners u4 and d1 first among 3 reviewers, got %v", created.Reviewers)
+	}
+	if created.ReviewerTeams["d1"] != "docs" {
+		t.Fatalf("expected d1 to come from docs team, got %v", created.ReviewerTeams)
+	}
+}
+
+func TestCreatePR_CodeOwnerIsAuthor(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", Codeowners: "* @u1"}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", ChangedFiles: []string{"main.go"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 1 || created.Reviewers[0] != "u2" {
+		t.Fatalf("expected author to be skipped and u2 assigned, got %v", created.Reviewers)
+	}
+}
+
+func TestPRLifecycle_Transitions(t *testing.T) {
+	tests := []struct {
+		name string
+		from domain.PRStatus
+		do   func(u *PRUsecase, ctx context.Context, prID string) (domain.PullRequest, error)
+		want domain.PRStatus
+		err  error
+	}{
+		{"close open", domain.StatusOpen, (*PRUsecase).ClosePR, domain.StatusClosed, nil},
+		{"reopen closed", domain.StatusClosed, (*PRUsecase).ReopenPR, domain.StatusReopened, nil},
+		{"merge reopened", domain.StatusReopened, (*PRUsecase).MergePR, domain.StatusMerged, nil},
+		{"draft to ready", domain.StatusDraft, (*PRUsecase).MarkReady, domain.StatusOpen, nil},
+		{"open to draft", domain.StatusOpen, (*PRUsecase).MarkDraft, domain.StatusDraft, nil},
+		{"merge closed", domain.StatusClosed, (*PRUsecase).MergePR, domain.StatusClosed, ErrInvalidTransition},
+		{"merge draft", domain.StatusDraft, (*PRUsecase).MergePR, domain.StatusDraft, ErrInvalidTransition},
+		{"reopen merged", domain.StatusMerged, (*PRUsecase).ReopenPR, domain.StatusMerged, ErrInvalidTransition},
+		{"close merged", domain.StatusMerged, (*PRUsecase).ClosePR, domain.StatusMerged, ErrInvalidTransition},
+		{"reopen open", domain.StatusOpen, (*PRUsecase).ReopenPR, domain.StatusOpen, ErrInvalidTransition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemRepo()
+			repo.prs["pr1"] = domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: tt.from}
+			u := NewPRUsecase(repo)
+			_, err := tt.do(u, context.Background(), "pr1")
+			if err != tt.err {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if got := repo.prs["pr1"].Status; got != tt.want {
+				t.Fatalf("expected status %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreatePR_Draft(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "wip", AuthorID: "u1", Status: domain.StatusDraft})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if created.Status != domain.StatusDraft {
+		t.Fatalf("expected DRAFT got %s", created.Status)
+	}
+}
+
+func TestReassignReviewer_ClosedPR(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := setupTeamWithUsers(repo, "backend", []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", Title: "fix", AuthorID: "u1", Status: domain.StatusClosed}, []string{"u2"})
+	u := NewPRUsecase(repo)
+	_, err := u.ReassignReviewer(ctx, "pr1", "u2")
+	assertError(t, err, ErrPRClosed, "expected ErrPRClosed")
+}
+
+func TestSubmitReview_LatestDecision(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: domain.StatusOpen}, []string{"u2", "u3"})
+	u := NewPRUsecase(repo)
+
+	for _, d := range []domain.ReviewDecision{domain.DecisionChangesRequested, domain.DecisionApproved} {
+		if _, err := u.SubmitReview(ctx, domain.Review{PullRequestID: "pr1", ReviewerID: "u2", Decision: d}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	pr, err := repo.GetPR(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got := pr.ReviewDecisions["u2"].Decision; got != domain.DecisionApproved {
+		t.Fatalf("expected APPROVED got %s", got)
+	}
+	if _, ok := pr.ReviewDecisions["u3"]; ok {
+		t.Fatalf("expected no decision for u3")
+	}
+}
+
+func TestSubmitReview_Errors(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: domain.StatusOpen}, []string{"u2"})
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr2", Title: "old", AuthorID: "u1", Status: domain.StatusMerged}, []string{"u2"})
+	u := NewPRUsecase(repo)
+
+	_, err := u.SubmitReview(ctx, domain.Review{PullRequestID: "pr1", ReviewerID: "u2", Decision: "LGTM"})
+	assertError(t, err, ErrValidation, "expected ErrValidation")
+	_, err = u.SubmitReview(ctx, domain.Review{PullRequestID: "pr1", ReviewerID: "u3", Decision: domain.DecisionApproved})
+	assertError(t, err, ErrNotAssigned, "expected ErrNotAssigned")
+	_, err = u.SubmitReview(ctx, domain.Review{PullRequestID: "pr2", ReviewerID: "u2", Decision: domain.DecisionApproved})
+	assertError(t, err, ErrPRMerged, "expected ErrPRMerged")
+	_, err = u.SubmitReview(ctx, domain.Review{PullRequestID: "nope", ReviewerID: "u2", Decision: domain.DecisionApproved})
+	assertError(t, err, ErrNotFound, "expected ErrNotFound")
+}
+
+func setupMergePolicyPR(t *testing.T, policy domain.Team) *memRepo {
+	t.Helper()
+	repo := newMemRepo()
+	policy.Name = "backend"
+	err := repo.addTeam(context.Background(), policy, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1", Status: domain.StatusOpen}, []string{"u2", "u3"})
+	return repo
+}
+
+func TestMergePR_BlockedByPolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := setupMergePolicyPR(t, domain.Team{RequiredApprovals: 2})
+	u := NewPRUsecase(repo)
+	if _, err := u.SubmitReview(ctx, domain.Review{PullRequestID: "pr1", ReviewerID: "u2", Decision: domain.DecisionApproved}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, err := u.SubmitReview(ctx, domain.Review{PullRequestID: "pr1", ReviewerID: "u3", Decision: domain.DecisionChangesRequested}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	_, err := u.MergePR(ctx, "pr1")
+	var blocked *MergeBlockedError
+	if !errors.As(err, &blocked) || !errors.Is(err, ErrMergeBlocked) {
+		t.Fatalf("expected MergeBlockedError, got %v", err)
+	}
+	want := []string{"required 2 approvals, got 1", "changes requested by u3"}
+	if !reflect.DeepEqual(blocked.Unmet, want) {
+		t.Fatalf("expected %v got %v", want, blocked.Unmet)
+	}
+	if repo.prs["pr1"].Status != domain.StatusOpen {
+		t.Fatalf("expected PR to stay OPEN, got %s", repo.prs["pr1"].Status)
+	}
+}
+
+func TestMergePR_PolicySatisfied(t *testing.T) {
+	ctx := context.Background()
+	repo := setupMergePolicyPR(t, domain.Team{RequiredApprovals: 1})
+	u := NewPRUsecase(repo)
+	for _, d := range []domain.ReviewDecision{domain.DecisionChangesRequested, domain.DecisionApproved} {
+		if _, err := u.SubmitReview(ctx, domain.Review{PullRequestID: "pr1", ReviewerID: "u3", Decision: d}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	pr, err := u.MergePR(ctx, "pr1")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if pr.Status != domain.StatusMerged || pr.MergeForcedBy != "" {
+		t.Fatalf("expected plain merge, got %s forced by %q", pr.Status, pr.MergeForcedBy)
+	}
+}
+
+func TestForceMergePR_RecordsAdmin(t *testing.T) {
+	ctx := context.Background()
+	repo := setupMergePolicyPR(t, domain.Team{RequiredApprovals: 2})
+	u := NewPRUsecase(repo)
+
+	_, err := u.ForceMergePR(ctx, "pr1", "")
+	assertError(t, err, ErrValidation, "expected ErrValidation")
+	pr, err := u.ForceMergePR(ctx, "pr1", "admin")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if pr.Status != domain.StatusMerged || pr.MergeForcedBy != "admin" {
+		t.Fatalf("expected merge forced by admin, got %s forced by %q", pr.Status, pr.MergeForcedBy)
+	}
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+// ReviewerSelector выбирает до n ревьюверов из кандидатов команды.
+// Кандидаты приходят с текущей нагрузкой в OpenReviews.
+type ReviewerSelector interface {
+	Select(ctx context.Context, teamID int, candidates []domain.User, n int) ([]string, error)
+}
+
+// tieredSelector выбирает до n ревьюверов из групп кандидатов по порядку: следующая
+// группа используется, только если предыдущих не хватило. Её реализуют стратегии с
+// состоянием, чтобы выбор для одного PR менял состояние один раз.
+type tieredSelector interface {
+	SelectTiered(ctx context.Context, teamID int, tiers [][]domain.User, n int) ([]string, error)
+}
+
+// lockedRand - *rand.Rand, безопасный для конкурентного использования
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	//nolint:gosec // math/rand is sufficient for non-cryptographic shuffling
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+func shuffle(r *lockedRand, ids []string) {
+	for i := range ids {
+		j := r.Intn(i + 1)
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+}
+
+func userIDs(users []domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func openReviews(users []domain.User) map[string]int {
+	load := make(map[string]int, len(users))
+	for _, u := range users {
+		load[u.ID] = u.OpenReviews
+	}
+	return load
+}
+
+type randomSelector struct {
+	rand *lockedRand
+}
+
+func (s *randomSelector) Select(ctx context.Context, teamID int, candidates []domain.User, n int) ([]string, error) {
+	ids := userIDs(candidates)
+	shuffle(s.rand, ids)
+	return pickUpTo(ids, n), nil
+}
+
+// roundRobinSelector идёт по участникам команды в порядке id, продолжая с места
+// последнего назначения. Позиция хранится в памяти процесса.
+type roundRobinSelector struct {
+	mu     sync.Mutex
+	lastID map[int]string
+}
+
+func (s *roundRobinSelector) Select(ctx context.Context, teamID int, candidates []domain.User, n int) ([]string, error) {
+	return s.SelectTiered(ctx, teamID, [][]domain.User{candidates}, n)
+}
+
+// SelectTiered обходит каждую группу с одной и той же позиции последнего назначения,
+// так что добор из следующей группы не сдвигает очередь предыдущей.
+func (s *roundRobinSelector) SelectTiered(ctx context.Context, teamID int, tiers [][]domain.User, n int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last := s.lastID[teamID]
+	var chosen []string
+	for _, tier := range tiers {
+		if len(chosen) >= n {
+			break
+		}
+		picked := rotateFrom(userIDs(tier), last, n-len(chosen))
+		if len(picked) > 0 {
+			chosen = append(chosen, picked...)
+			s.lastID[teamID] = picked[len(picked)-1]
+		}
+	}
+	return chosen, nil
+}
+
+// rotateFrom возвращает до n id по порядку, начиная со следующего за last
+func rotateFrom(ids []string, last string, n int) []string {
+	if len(ids) == 0 || n <= 0 {
+		return nil
+	}
+	sort.Strings(ids)
+	start := sort.SearchStrings(ids, last)
+	if start < len(ids) && ids[start] == last {
+		start++
+	}
+	if n > len(ids) {
+		n = len(ids)
+	}
+	chosen := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		chosen = append(chosen, ids[(start+i)%len(ids)])
+	}
+	return chosen
+}
+
+// leastLoadedSelector предпочитает кандидатов с наименьшим числом открытых ревью,
+// при равной нагрузке порядок случайный.
+type leastLoadedSelector struct {
+	rand *lockedRand
+}
+
+func (s *leastLoadedSelector) Select(ctx context.Context, teamID int, candidates []domain.User, n int) ([]string, error) {
+	load := openReviews(candidates)
+	ids := userIDs(candidates)
+	shuffle(s.rand, ids)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return load[ids[i]] < load[ids[j]]
+	})
+	return pickUpTo(ids, n), nil
+}
+
+// weightedRandomSelector выбирает случайно с весом 1/(1+открытые ревью),
+// так что загруженные участники выбираются реже, но не исключаются.
+type weightedRandomSelector struct {
+	rand *lockedRand
+}
+
+func (s *weightedRandomSelector) Select(ctx context.Context, teamID int, candidates []domain.User, n int) ([]string, error) {
+	load := openReviews(candidates)
+	ids := userIDs(candidates)
+	weights := make([]float64, len(ids))
+	for i, id := range ids {
+		weights[i] = 1 / float64(1+load[id])
+	}
+
+	var chosen []string
+	for len(chosen) < n && len(ids) > 0 {
+		var total float64
+		for _, w := range weights {
+			total += w
+		}
+		x := s.rand.Float64() * total
+		i := 0
+		for ; i < len(weights)-1; i++ {
+			x -= weights[i]
+			if x < 0 {
+				break
+			}
+		}
+		chosen = append(chosen, ids[i])
+		ids = append(ids[:i], ids[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return chosen, nil
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+)
+
+func candidates(ids ...string) []domain.User {
+	users := make([]domain.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, domain.User{ID: id, IsActive: true})
+	}
+	return users
+}
+
+func TestRoundRobinSelector_Rotates(t *testing.T) {
+	ctx := context.Background()
+	s := &roundRobinSelector{lastID: map[int]string{}}
+	cands := candidates("u3", "u1", "u2")
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		picked, err := s.Select(ctx, 1, cands, 1)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		got = append(got, picked...)
+	}
+	expected := []string{"u1", "u2", "u3", "u1"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestRoundRobinSelector_PerTeam(t *testing.T) {
+	ctx := context.Background()
+	s := &roundRobinSelector{lastID: map[int]string{}}
+	if _, err := s.Select(ctx, 1, candidates("a", "b"), 1); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	picked, err := s.Select(ctx, 2, candidates("a", "b"), 1)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if picked[0] != "a" {
+		t.Fatalf("expected other team to start from a, got %s", picked[0])
+	}
+}
+
+func TestRoundRobinSelector_TieredKeepsRotation(t *testing.T) {
+	ctx := context.Background()
+	s := &roundRobinSelector{lastID: map[int]string{}}
+	matched, rest := candidates("d"), candidates("a", "b", "c")
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		picked, err := s.SelectTiered(ctx, 1, [][]domain.User{matched, rest}, 2)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		got = append(got, picked...)
+	}
+	// добор из общего пула идёт по кругу, а не с места, где оказался d
+	expected := []string{"d", "a", "d", "b", "d", "c"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func withLoad(users []domain.User, load map[string]int) []domain.User {
+	for i := range users {
+		users[i].OpenReviews = load[users[i].ID]
+	}
+	return users
+}
+
+func TestLeastLoadedSelector_PrefersIdle(t *testing.T) {
+	ctx := context.Background()
+	s := &leastLoadedSelector{rand: newLockedRand(1)}
+	cands := withLoad(candidates("u1", "u2", "u3"), map[string]int{"u1": 2, "u2": 1})
+	picked, err := s.Select(ctx, 1, cands, 2)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(picked) != 2 || picked[0] != "u3" || picked[1] != "u2" {
+		t.Fatalf("expected [u3 u2], got %v", picked)
+	}
+}
+
+func TestLeastLoadedSelector_RandomTieBreak(t *testing.T) {
+	ctx := context.Background()
+	s := &leastLoadedSelector{rand: newLockedRand(1)}
+	cands := withLoad(candidates("u1", "u2", "u3"), map[string]int{"u3": 5})
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		picked, err := s.Select(ctx, 1, cands, 1)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if picked[0] == "u3" {
+			t.Fatalf("most loaded candidate must not be picked first")
+		}
+		seen[picked[0]] = true
+	}
+	if !seen["u1"] || !seen["u2"] {
+		t.Fatalf("expected ties to be broken randomly, saw %v", seen)
+	}
+}
+
+func TestWeightedRandomSelector_NoDuplicates(t *testing.T) {
+	ctx := context.Background()
+	s := &weightedRandomSelector{rand: newLockedRand(1)}
+	for i := 0; i < 20; i++ {
+		picked, err := s.Select(ctx, 1, withLoad(candidates("u1", "u2", "u3"), map[string]int{"u1": 1}), 3)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(picked) != 3 {
+			t.Fatalf("expected 3 reviewers, got %v", picked)
+		}
+		seen := map[string]bool{}
+		for _, id := range picked {
+			if seen[id] {
+				t.Fatalf("duplicate reviewer in %v", picked)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestCreatePR_UsesTeamStrategy(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", ReviewerStrategy: domain.StrategyRoundRobin}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+		{ID: "u4", Username: "dave", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	u := NewPRUsecase(repo)
+	first, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "a", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	second, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr2", Title: "b", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if first.Reviewers[0] != "u2" || first.Reviewers[1] != "u3" {
+		t.Fatalf("expected [u2 u3], got %v", first.Reviewers)
+	}
+	if second.Reviewers[0] != "u4" || second.Reviewers[1] != "u2" {
+		t.Fatalf("expected [u4 u2], got %v", second.Reviewers)
+	}
+}
+
+func TestCreatePR_LeastLoadedUsesOpenReviews(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo()
+	if err := repo.addTeam(ctx, domain.Team{Name: "backend", ReviewerStrategy: domain.StrategyLeastLoaded, MaxReviewers: 1}, []domain.User{
+		{ID: "u1", Username: "alice", IsActive: true},
+		{ID: "u2", Username: "bob", IsActive: true},
+		{ID: "u3", Username: "carl", IsActive: true},
+	}); err != nil {
+		t.Fatalf("failed to create team: %v", err)
+	}
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "old", AuthorID: "u1", Status: "OPEN"}, []string{"u2"})
+	setupPRWithReviewers(repo, domain.PullRequest{ID: "done", AuthorID: "u1", Status: "MERGED"}, []string{"u3"})
+	u := NewPRUsecase(repo)
+	created, err := u.CreatePR(ctx, domain.PullRequest{ID: "pr1", Title: "feat", AuthorID: "u1"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(created.Reviewers) != 1 || created.Reviewers[0] != "u3" {
+		t.Fatalf("expected least loaded u3, got %v", created.Reviewers)
+	}
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+)
+
+// Заголовки исходящих вызовов
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Event-Id"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// SubscriptionStore - откуда берутся подписки (реализуется repository.Repo)
+type SubscriptionStore interface {
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+}
+
+// Dispatcher доставляет событие подписчикам. Повторы недоставленных событий
+// выполняет relay outbox, поэтому один вызов делает одну попытку на подписку.
+type Dispatcher struct {
+	Store  SubscriptionStore
+	Client *http.Client
+	Log    infra.Logger
+}
+
+func NewDispatcher(store SubscriptionStore, log infra.Logger) *Dispatcher {
+	return &Dispatcher{
+		Store:  store,
+		Client: &http.Client{Timeout: defaultTimeout},
+		Log:    log,
+	}
+}
+
+// Sign возвращает подпись тела запроса: HMAC-SHA256 с секретом подписки.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhook"
+}
+
+// Deliver отправляет событие всем подписанным на его тип вебхукам и возвращает
+// ошибку, если хотя бы одну доставку стоит повторить. Отказ подписчика с 4xx
+// повтора не требует и только логируется.
+func (d *Dispatcher) Deliver(ctx context.Context, e domain.Event) error {
+	subs, err := d.Store.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("list subscriptions: %w", err)
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(subs))
+	for _, sub := range subs {
+		if !sub.Wants(e.Type) {
+			continue
+		}
+		wg.Add(1)
+		go func(sub domain.Webhook) {
+			defer wg.Done()
+			retry, err := d.send(ctx, sub, e, body)
+			switch {
+			case err == nil:
+			case retry:
+				errs <- fmt.Errorf("%s: %w", sub.URL, err)
+			default:
+				d.Log.Errorf("Webhook: event %s rejected by %s: %v", e.ID, sub.URL, err)
+			}
+		}(sub)
+	}
+	wg.Wait()
+	close(errs)
+
+	var failed []error
+	for err := range errs {
+		failed = append(failed, err)
+	}
+	return errors.Join(failed...)
+}
+
+// send выполняет одну попытку; retry сообщает, имеет ли смысл повторить.
+func (d *Dispatcher) send(ctx context.Context, sub domain.Webhook, e domain.Event, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, e.Type)
+	req.Header.Set(HeaderEventID, e.ID)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		// остальные 4xx повтором не исправить
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+)
+
+type staticStore []domain.Webhook
+
+func (s staticStore) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return s, nil
+}
+
+// receiver - локальный получатель вебхуков, отвечающий статусами из codes по очереди
+type receiver struct {
+	mu     sync.Mutex
+	codes  []int
+	bodies [][]byte
+	sigs   []string
+	types  []string
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.bodies = append(rc.bodies, body)
+	rc.sigs = append(rc.sigs, r.Header.Get(HeaderSignature))
+	rc.types = append(rc.types, r.Header.Get(HeaderEventType))
+	code := http.StatusOK
+	if n := len(rc.bodies); n <= len(rc.codes) {
+		code = rc.codes[n-1]
+	}
+	w.WriteHeader(code)
+}
+
+func (rc *receiver) calls() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.bodies)
+}
+
+func newTestDispatcher(subs ...domain.Webhook) *Dispatcher {
+	return NewDispatcher(staticStore(subs), infra.NewStdLogger())
+}
+
+func TestDispatcher_DeliversSignedEvent(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d := newTestDispatcher(domain.Webhook{ID: 1, URL: srv.URL, Secret: "s3cret"})
+
+	e := domain.NewEvent(domain.EventReviewerAssigned, domain.ReviewerAssignment{PullRequestID: "pr1", ReviewerID: "u2"})
+	if err := d.Deliver(context.Background(), e); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if rc.calls() != 1 {
+		t.Fatalf("expected 1 call, got %d", rc.calls())
+	}
+	if rc.sigs[0] != Sign("s3cret", rc.bodies[0]) {
+		t.Fatalf("signature mismatch: %s", rc.sigs[0])
+	}
+	if rc.types[0] != domain.EventReviewerAssigned {
+		t.Fatalf("expected event type header %s, got %s", domain.EventReviewerAssigned, rc.types[0])
+	}
+	var got struct {
+		ID   string                    `json:"event_id"`
+		Data domain.ReviewerAssignment `json:"data"`
+	}
+	if err := json.Unmarshal(rc.bodies[0], &got); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if got.ID != e.ID || got.Data.ReviewerID != "u2" {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+}
+
+func TestDispatcher_ReportsRetryableFailures(t *testing.T) {
+	failing := &receiver{codes: []int{http.StatusInternalServerError, http.StatusTooManyRequests}}
+	rejecting := &receiver{codes: []int{http.StatusBadRequest, http.StatusBadRequest}}
+	srvFailing := httptest.NewServer(failing)
+	defer srvFailing.Close()
+	srvRejecting := httptest.NewServer(rejecting)
+	defer srvRejecting.Close()
+
+	tests := []struct {
+		name    string
+		subs    []domain.Webhook
+		wantErr bool
+	}{
+		{"server error", []domain.Webhook{{ID: 1, URL: srvFailing.URL, Secret: "s"}}, true},
+		{"too many requests", []domain.Webhook{{ID: 1, URL: srvFailing.URL, Secret: "s"}}, true},
+		{"client error is not retried", []domain.Webhook{{ID: 2, URL: srvRejecting.URL, Secret: "s"}}, false},
+		{"unreachable", []domain.Webhook{{ID: 3, URL: "http://127.0.0.1:1", Secret: "s"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestDispatcher(tt.subs...).Deliver(context.Background(), domain.NewEvent(domain.EventPRMerged, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDispatcher_EventFilter(t *testing.T) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d := newTestDispatcher(domain.Webhook{ID: 1, URL: srv.URL, Secret: "s", Events: []string{domain.EventPRMerged}})
+
+	for _, e := range []domain.Event{domain.NewEvent(domain.EventPRCreated, nil), domain.NewEvent(domain.EventPRMerged, nil)} {
+		if err := d.Deliver(context.Background(), e); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+
+	if rc.calls() != 1 || rc.types[0] != domain.EventPRMerged {
+		t.Fatalf("expected only %s delivered, got %v", domain.EventPRMerged, rc.types)
+	}
+}
@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/notify"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// digestLockKey - ключ advisory-блокировки рассылки дайджеста
+const digestLockKey int64 = 7_022_001
+
+const (
+	defaultDigestRetryInterval = 15 * time.Minute
+	defaultDigestMaxAttempts   = 5
+)
+
+// DigestSource - получатели дайджеста и их ревью (реализуется pg.PGRepo)
+type DigestSource interface {
+	DigestRecipients(ctx context.Context, day, retryBefore time.Time) ([]domain.User, error)
+	GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error)
+	MarkDigestSent(ctx context.Context, userID string, day time.Time) error
+	MarkDigestFailed(ctx context.Context, userID string, day, at time.Time) (int, error)
+}
+
+// Mailer отправляет письма (реализуется notify.SMTP)
+type Mailer interface {
+	Send(ctx context.Context, e notify.Email) error
+}
+
+// Digest раз в день после SendAt рассылает пользователям сводку PR, ждущих их ревью.
+// Отправка отмечается у пользователя, поэтому перезапуск и несколько реплик не
+// дублируют письма. Не отправленное из-за ошибки письмо повторяется не раньше чем
+// через RetryInterval, а после MaxAttempts неудач за день пропускается до следующего дня.
+// Если письмо ушло, а отметить отправку не удалось, повторяется только отметка.
+type Digest struct {
+	Source DigestSource
+	Mailer Mailer
+	Locker repository.Locker
+	Log    infra.Logger
+	// SendAt - время отправки от начала суток в Location
+	SendAt   time.Duration
+	Location *time.Location
+	// Interval - как часто проверять, не пора ли отправлять
+	Interval time.Duration
+	// RetryInterval - пауза после неудачной отправки; MaxAttempts - после стольких
+	// неудач за день дайджест за этот день больше не отправляется
+	RetryInterval time.Duration
+	MaxAttempts   int
+
+	// unmarked - день, за который пользователю уже отправлено письмо без отметки об отправке
+	unmarked map[string]time.Time
+}
+
+func NewDigest(source DigestSource, mailer Mailer, locker repository.Locker, log infra.Logger, sendAt time.Duration, loc *time.Location) *Digest {
+	return &Digest{
+		Source:        source,
+		Mailer:        mailer,
+		Locker:        locker,
+		Log:           log,
+		SendAt:        sendAt,
+		Location:      loc,
+		Interval:      time.Minute,
+		RetryInterval: defaultDigestRetryInterval,
+		MaxAttempts:   defaultDigestMaxAttempts,
+		unmarked:      map[string]time.Time{},
+	}
+}
+
+// Run проверяет раз в Interval, пока не отменён ctx.
+func (d *Digest) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.RunOnce(ctx, time.Now())
+		}
+	}
+}
+
+// RunOnce рассылает дайджест за день now, если время отправки уже наступило.
+func (d *Digest) RunOnce(ctx context.Context, now time.Time) {
+	local := now.In(d.Location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, d.Location)
+	if local.Before(day.Add(d.SendAt)) {
+		return
+	}
+	_, err := d.Locker.WithAdvisoryLock(ctx, digestLockKey, func(ctx context.Context) error {
+		users, err := d.Source.DigestRecipients(ctx, day, now.Add(-d.RetryInterval))
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if err := d.send(ctx, u, day); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				d.fail(ctx, u, day, now, err)
+			}
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		d.Log.Errorf("Digest: pass failed: %v", err)
+	}
+}
+
+// send отправляет дайджест одному пользователю; без ожидающих ревью письмо не нужно.
+// Ошибка означает, что письмо не отправлено: ошибка отметки после отправки только
+// логируется, а следующий проход повторит одну отметку, не отправляя письмо снова.
+func (d *Digest) send(ctx context.Context, u domain.User, day time.Time) error {
+	if !d.unmarked[u.ID].Equal(day) {
+		prs, err := d.Source.GetUserReviews(ctx, u.ID)
+		if err != nil {
+			return err
+		}
+		if pending := notify.PendingReviews(prs); len(pending) > 0 {
+			if err := d.Mailer.Send(ctx, notify.DigestEmail(u, pending)); err != nil {
+				return err
+			}
+			d.Log.Infof("Digest: sent %d pending reviews to user %s", len(pending), u.ID)
+		}
+	}
+	if err := d.Source.MarkDigestSent(ctx, u.ID, day); err != nil {
+		d.unmarked[u.ID] = day
+		d.Log.Errorf("Digest: user %s: digest for %s sent but not marked: %v", u.ID, day.Format(time.DateOnly), err)
+		return nil
+	}
+	delete(d.unmarked, u.ID)
+	return nil
+}
+
+// fail записывает неудачную отправку; после MaxAttempts неудач за день дайджест за
+// этот день отмечается отправленным, чтобы не повторять его весь день
+func (d *Digest) fail(ctx context.Context, u domain.User, day, now time.Time, sendErr error) {
+	failures, err := d.Source.MarkDigestFailed(ctx, u.ID, day, now)
+	if err != nil {
+		d.Log.Errorf("Digest: user %s: %v; failed to record the failure: %v", u.ID, sendErr, err)
+		return
+	}
+	if failures < d.MaxAttempts {
+		d.Log.Errorf("Digest: user %s: attempt %d failed, retry in %s: %v", u.ID, failures, d.RetryInterval, sendErr)
+		return
+	}
+	d.Log.Errorf("Digest: user %s: giving up on %s after %d attempts: %v", u.ID, day.Format(time.DateOnly), failures, sendErr)
+	if err := d.Source.MarkDigestSent(ctx, u.ID, day); err != nil {
+		d.Log.Errorf("Digest: user %s: %v", u.ID, err)
+	}
+}
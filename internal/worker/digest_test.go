@@ -0,0 +1,202 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/notify"
+	"github.com/you/pr-assign-avito/internal/notify/smtptest"
+)
+
+// memDigestSource хранит пользователей, их ревью, день последнего дайджеста и неудачные отправки
+type memDigestSource struct {
+	users    []domain.User
+	reviews  map[string][]domain.PullRequest
+	sentOn   map[string]string
+	failedAt map[string]time.Time
+	failures map[string]int
+	// markErr - ошибка, с которой не удаётся отметить отправку
+	markErr error
+}
+
+func (m *memDigestSource) DigestRecipients(ctx context.Context, day, retryBefore time.Time) ([]domain.User, error) {
+	var res []domain.User
+	for _, u := range m.users {
+		failedAt, failed := m.failedAt[u.ID]
+		if u.IsActive && u.Email != "" && !u.DigestOptOut && m.sentOn[u.ID] < day.Format(time.DateOnly) &&
+			(!failed || !failedAt.After(retryBefore)) {
+			res = append(res, u)
+		}
+	}
+	return res, nil
+}
+
+func (m *memDigestSource) GetUserReviews(ctx context.Context, userID string) ([]domain.PullRequest, error) {
+	return m.reviews[userID], nil
+}
+
+func (m *memDigestSource) MarkDigestSent(ctx context.Context, userID string, day time.Time) error {
+	if m.markErr != nil {
+		return m.markErr
+	}
+	m.sentOn[userID] = day.Format(time.DateOnly)
+	return nil
+}
+
+func (m *memDigestSource) MarkDigestFailed(ctx context.Context, userID string, day, at time.Time) (int, error) {
+	if m.failedAt[userID].Before(day) {
+		m.failures[userID] = 0
+	}
+	m.failures[userID]++
+	m.failedAt[userID] = at
+	return m.failures[userID], nil
+}
+
+func newDigestFixture(t *testing.T) (*Digest, *memDigestSource, *smtptest.Server) {
+	t.Helper()
+	srv, err := smtptest.NewServer()
+	if err != nil {
+		t.Fatalf("failed to start smtp server: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	source := &memDigestSource{
+		users: []domain.User{
+			{ID: "u1", Username: "alice", IsActive: true, Email: "alice@example.com"},
+			{ID: "u2", Username: "bob", IsActive: true, Email: "bob@example.com"},
+			{ID: "u3", Username: "carl", IsActive: true, Email: "carl@example.com", DigestOptOut: true},
+			{ID: "u4", Username: "dana", IsActive: true},
+		},
+		reviews: map[string][]domain.PullRequest{
+			"u1": {{ID: "pr-1", Title: "Add search", Status: domain.StatusOpen}},
+			"u2": {{ID: "pr-2", Title: "Done", Status: domain.StatusMerged}},
+			"u3": {{ID: "pr-1", Title: "Add search", Status: domain.StatusOpen}},
+			"u4": {{ID: "pr-1", Title: "Add search", Status: domain.StatusOpen}},
+		},
+		sentOn:   map[string]string{},
+		failedAt: map[string]time.Time{},
+		failures: map[string]int{},
+	}
+	mailer := notify.NewSMTP(srv.Addr, "reviews@example.com", "", "")
+	loc := time.FixedZone("MSK", 3*60*60)
+	d := NewDigest(source, mailer, &fakeLocker{}, infra.NewStdLogger(), 9*time.Hour, loc)
+	return d, source, srv
+}
+
+func recipients(msgs []smtptest.Message) []string {
+	res := []string{}
+	for _, m := range msgs {
+		res = append(res, m.To...)
+	}
+	return res
+}
+
+func TestDigest_SendsOncePerDayAfterSendTime(t *testing.T) {
+	d, source, srv := newDigestFixture(t)
+	ctx := context.Background()
+
+	// 08:59 MSK - ещё рано
+	d.RunOnce(ctx, time.Date(2026, 10, 16, 5, 59, 0, 0, time.UTC))
+	if len(srv.Messages()) != 0 {
+		t.Fatalf("expected no digest before send time, got %v", recipients(srv.Messages()))
+	}
+
+	d.RunOnce(ctx, time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	d.RunOnce(ctx, time.Date(2026, 10, 16, 6, 1, 0, 0, time.UTC))
+	if got := recipients(srv.Messages()); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Fatalf("expected one digest for alice, got %v", got)
+	}
+	// bob проверен, но без ожидающих ревью письмо не нужно
+	if source.sentOn["u2"] != "2026-10-16" {
+		t.Fatalf("expected bob marked for 2026-10-16, got %q", source.sentOn["u2"])
+	}
+
+	d.RunOnce(ctx, time.Date(2026, 10, 17, 6, 30, 0, 0, time.UTC))
+	if got := recipients(srv.Messages()); !reflect.DeepEqual(got, []string{"alice@example.com", "alice@example.com"}) {
+		t.Fatalf("expected next day digest, got %v", got)
+	}
+}
+
+func TestDigest_RetriesFailedSend(t *testing.T) {
+	d, source, srv := newDigestFixture(t)
+	ctx := context.Background()
+	srv.RejectRcpt["alice@example.com"] = true
+
+	d.RunOnce(ctx, time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC))
+	if _, marked := source.sentOn["u1"]; marked {
+		t.Fatalf("expected failed digest not to be marked sent")
+	}
+
+	delete(srv.RejectRcpt, "alice@example.com")
+	// до RetryInterval после неудачи отправка не повторяется
+	d.RunOnce(ctx, time.Date(2026, 10, 16, 7, 1, 0, 0, time.UTC))
+	if len(srv.Messages()) != 0 {
+		t.Fatalf("expected no retry before the retry interval, got %v", recipients(srv.Messages()))
+	}
+	d.RunOnce(ctx, time.Date(2026, 10, 16, 7, 15, 0, 0, time.UTC))
+	if got := recipients(srv.Messages()); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Fatalf("expected digest on retry, got %v", got)
+	}
+}
+
+func TestDigest_GivesUpForTheDayAfterMaxAttempts(t *testing.T) {
+	d, source, srv := newDigestFixture(t)
+	ctx := context.Background()
+	srv.RejectRcpt["alice@example.com"] = true
+
+	// проходы каждую минуту с 10:00 до 12:00 MSK
+	start := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	for now := start; now.Before(start.Add(2 * time.Hour)); now = now.Add(time.Minute) {
+		d.RunOnce(ctx, now)
+	}
+	if source.failures["u1"] != d.MaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", d.MaxAttempts, source.failures["u1"])
+	}
+	if source.sentOn["u1"] != "2026-10-16" {
+		t.Fatalf("expected alice skipped for 2026-10-16, got %q", source.sentOn["u1"])
+	}
+
+	// на следующий день попытки начинаются заново
+	delete(srv.RejectRcpt, "alice@example.com")
+	d.RunOnce(ctx, time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC))
+	if got := recipients(srv.Messages()); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Fatalf("expected next day digest, got %v", got)
+	}
+}
+
+func TestDigest_DoesNotResendWhenMarkFails(t *testing.T) {
+	d, source, srv := newDigestFixture(t)
+	ctx := context.Background()
+	source.markErr = errors.New("connection reset")
+
+	start := time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC)
+	for now := start; now.Before(start.Add(time.Hour)); now = now.Add(time.Minute) {
+		d.RunOnce(ctx, now)
+	}
+	if got := recipients(srv.Messages()); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Fatalf("expected a single digest despite the marker failing, got %v", got)
+	}
+	if source.failures["u1"] != 0 {
+		t.Fatalf("expected marker failure not to count as a failed send, got %d", source.failures["u1"])
+	}
+
+	source.markErr = nil
+	d.RunOnce(ctx, start.Add(time.Hour))
+	if source.sentOn["u1"] != "2026-10-16" || len(srv.Messages()) != 1 {
+		t.Fatalf("expected only the mark to be retried, got sentOn=%q messages=%v", source.sentOn["u1"], recipients(srv.Messages()))
+	}
+}
+
+func TestDigest_SkipsWhenLockHeld(t *testing.T) {
+	d, _, srv := newDigestFixture(t)
+	d.Locker = &fakeLocker{heldElsewhere: true}
+
+	d.RunOnce(context.Background(), time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC))
+	if len(srv.Messages()) != 0 {
+		t.Fatalf("expected pass to be skipped, got %v", recipients(srv.Messages()))
+	}
+}
@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// escalationLockKey - ключ advisory-блокировки, под которой проход эскалации
+// выполняет только одна реплика
+const escalationLockKey int64 = 7_015_001
+
+// escalatorActor - инициатор эскалаций в журнале назначений
+const escalatorActor = "system:escalator"
+
+// StaleReviewEscalator - эскалация зависших ревью (реализуется usecase.PRUsecase)
+type StaleReviewEscalator interface {
+	EscalateStaleReviews(ctx context.Context, now time.Time) (domain.EscalationReport, error)
+}
+
+// AbsenceReassigner - переназначение открытых ревью при начале отсутствия
+// ревьювера (реализуется usecase.PRUsecase)
+type AbsenceReassigner interface {
+	ReassignStartedAbsences(ctx context.Context, now time.Time) (domain.ReassignReport, error)
+}
+
+// Escalator периодически эскалирует зависшие ревью и переназначает ревью
+// пользователей, чьё запланированное отсутствие началось.
+type Escalator struct {
+	UC       StaleReviewEscalator
+	Absences AbsenceReassigner
+	Locker   repository.Locker
+	Log      infra.Logger
+	Interval time.Duration
+}
+
+func NewEscalator(uc StaleReviewEscalator, absences AbsenceReassigner, locker repository.Locker, log infra.Logger, interval time.Duration) *Escalator {
+	return &Escalator{UC: uc, Absences: absences, Locker: locker, Log: log, Interval: interval}
+}
+
+// Run выполняет проходы раз в Interval, пока не отменён ctx.
+func (e *Escalator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce выполняет один проход, если эту работу не делает сейчас другая реплика.
+func (e *Escalator) RunOnce(ctx context.Context) {
+	// если блокировку держит другая реплика, проход просто пропускается
+	_, err := e.Locker.WithAdvisoryLock(ctx, escalationLockKey, func(ctx context.Context) error {
+		ctx = domain.WithActor(ctx, escalatorActor)
+		now := time.Now().UTC()
+		reassigned, err := e.Absences.ReassignStartedAbsences(ctx, now)
+		if err != nil {
+			return err
+		}
+		for _, r := range reassigned.Reassigned {
+			e.Log.Infof("Escalator: PR %s reviewer %s absent, new reviewer %s",
+				r.PullRequestID, r.OldReviewerID, r.NewReviewerID)
+		}
+		for _, f := range reassigned.Failed {
+			e.Log.Errorf("Escalator: PR %s absent reviewer %s not reassigned: %s", f.PullRequestID, f.ReviewerID, f.Reason)
+		}
+		report, err := e.UC.EscalateStaleReviews(ctx, now)
+		if err != nil {
+			return err
+		}
+		for _, esc := range report.Escalated {
+			e.Log.Infof("Escalator: PR %s reviewer %s escalated (%s), new reviewer %s",
+				esc.PullRequestID, esc.ReviewerID, esc.Action, esc.NewReviewerID)
+		}
+		for _, f := range report.Failed {
+			e.Log.Errorf("Escalator: PR %s reviewer %s not escalated: %s", f.PullRequestID, f.ReviewerID, f.Reason)
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		e.Log.Errorf("Escalator: pass failed: %v", err)
+	}
+}
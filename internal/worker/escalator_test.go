@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+)
+
+type fakeEscalator struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakeEscalator) EscalateStaleReviews(ctx context.Context, now time.Time) (domain.EscalationReport, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return domain.EscalationReport{}, nil
+}
+
+func (f *fakeEscalator) ReassignStartedAbsences(ctx context.Context, now time.Time) (domain.ReassignReport, error) {
+	return domain.NewReassignReport(), nil
+}
+
+func (f *fakeEscalator) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// fakeLocker имитирует advisory-блокировку, которую может держать другая реплика
+type fakeLocker struct {
+	heldElsewhere bool
+}
+
+func (l *fakeLocker) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	if l.heldElsewhere {
+		return false, nil
+	}
+	return true, fn(ctx)
+}
+
+func TestEscalator_RunOnce(t *testing.T) {
+	uc := &fakeEscalator{}
+	e := NewEscalator(uc, uc, &fakeLocker{}, infra.NewStdLogger(), time.Minute)
+	e.RunOnce(context.Background())
+	if uc.count() != 1 {
+		t.Fatalf("expected 1 pass, got %d", uc.count())
+	}
+}
+
+func TestEscalator_SkipsWhenLockHeld(t *testing.T) {
+	uc := &fakeEscalator{}
+	e := NewEscalator(uc, uc, &fakeLocker{heldElsewhere: true}, infra.NewStdLogger(), time.Minute)
+	e.RunOnce(context.Background())
+	if uc.count() != 0 {
+		t.Fatalf("expected pass to be skipped, got %d", uc.count())
+	}
+}
+
+func TestEscalator_RunStopsOnCancel(t *testing.T) {
+	uc := &fakeEscalator{}
+	e := NewEscalator(uc, uc, &fakeLocker{}, infra.NewStdLogger(), time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for uc.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Run did not stop after cancel")
+	}
+	if uc.count() == 0 {
+		t.Fatalf("expected at least one pass")
+	}
+}
@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/repository"
+)
+
+// outboxLockKey - ключ advisory-блокировки, под которой outbox разбирает только одна реплика
+const outboxLockKey int64 = 7_017_001
+
+const (
+	defaultOutboxBatchSize   = 100
+	defaultOutboxMaxAttempts = 10
+	defaultOutboxBaseBackoff = time.Second
+	maxOutboxBackoff         = time.Hour
+)
+
+// EventSink - получатель событий из outbox. Ошибка означает, что доставку нужно
+// повторить; получатель различает повторы по Event.ID. Name - постоянное имя
+// получателя, по нему relay запоминает, кто уже принял событие.
+type EventSink interface {
+	Name() string
+	Deliver(ctx context.Context, e domain.Event) error
+}
+
+// OutboxRelay доставляет события из outbox всем получателям. Событие считается
+// доставленным, только когда его приняли все получатели; при повторе оно уходит
+// только тем, кто его ещё не принял. Доставка - не менее одного раза.
+type OutboxRelay struct {
+	Outbox   repository.Outbox
+	Locker   repository.Locker
+	Sinks    []EventSink
+	Log      infra.Logger
+	Interval time.Duration
+	// BatchSize - сколько событий берётся за проход; MaxAttempts - после стольких
+	// неудачных попыток событие больше не доставляется
+	BatchSize   int
+	MaxAttempts int
+	// BaseBackoff - задержка перед второй попыткой; каждая следующая вдвое больше
+	BaseBackoff time.Duration
+}
+
+func NewOutboxRelay(outbox repository.Outbox, locker repository.Locker, log infra.Logger, interval time.Duration, sinks ...EventSink) *OutboxRelay {
+	return &OutboxRelay{
+		Outbox:      outbox,
+		Locker:      locker,
+		Sinks:       sinks,
+		Log:         log,
+		Interval:    interval,
+		BatchSize:   defaultOutboxBatchSize,
+		MaxAttempts: defaultOutboxMaxAttempts,
+		BaseBackoff: defaultOutboxBaseBackoff,
+	}
+}
+
+// Run выполняет проходы раз в Interval, пока не отменён ctx.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce доставляет очередную пачку событий, если outbox не разбирает другая реплика.
+func (r *OutboxRelay) RunOnce(ctx context.Context) {
+	_, err := r.Locker.WithAdvisoryLock(ctx, outboxLockKey, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		events, err := r.Outbox.PendingEvents(ctx, now, r.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, oe := range events {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := r.relay(ctx, oe, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		r.Log.Errorf("OutboxRelay: pass failed: %v", err)
+	}
+}
+
+// relay доставляет одно событие и записывает результат попытки
+func (r *OutboxRelay) relay(ctx context.Context, oe domain.OutboxEvent, now time.Time) error {
+	var errs []error
+	delivered := append([]string(nil), oe.DeliveredTo...)
+	for _, sink := range r.Sinks {
+		if slices.Contains(oe.DeliveredTo, sink.Name()) {
+			continue
+		}
+		if err := sink.Deliver(ctx, oe.Event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+			continue
+		}
+		delivered = append(delivered, sink.Name())
+	}
+	deliverErr := errors.Join(errs...)
+	if ctx.Err() != nil {
+		// попытку прервала остановка сервиса - событие останется в очереди как есть
+		return nil
+	}
+	if deliverErr == nil {
+		return r.Outbox.MarkEventDelivered(ctx, oe.Seq)
+	}
+
+	attempt := oe.Attempts + 1
+	reason := deliverErr.Error()
+	if attempt >= r.MaxAttempts {
+		r.Log.Errorf("OutboxRelay: event %s %s dropped after %d attempts: %v", oe.Event.Type, oe.Event.ID, attempt, deliverErr)
+		return r.Outbox.MarkEventDead(ctx, oe.Seq, reason)
+	}
+	r.Log.Errorf("OutboxRelay: event %s %s attempt %d failed: %v", oe.Event.Type, oe.Event.ID, attempt, deliverErr)
+	return r.Outbox.RetryEventLater(ctx, oe.Seq, reason, now.Add(r.backoff(attempt)), delivered)
+}
+
+// backoff возвращает задержку после attempt неудачных попыток
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	d := r.BaseBackoff
+	for i := 1; i < attempt && d < maxOutboxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxOutboxBackoff {
+		d = maxOutboxBackoff
+	}
+	return d
+}
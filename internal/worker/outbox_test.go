@@ -0,0 +1,219 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/you/pr-assign-avito/internal/domain"
+	"github.com/you/pr-assign-avito/internal/infra"
+	"github.com/you/pr-assign-avito/internal/usecase"
+)
+
+// memOutbox - outbox в памяти с тем же отбором событий, что и в PostgreSQL
+type memOutbox struct {
+	events    []domain.OutboxEvent
+	nextAt    map[int64]time.Time
+	delivered map[int64]bool
+	dead      map[int64]bool
+}
+
+func newMemOutbox(events ...domain.Event) *memOutbox {
+	o := &memOutbox{nextAt: map[int64]time.Time{}, delivered: map[int64]bool{}, dead: map[int64]bool{}}
+	for i, e := range events {
+		o.events = append(o.events, domain.OutboxEvent{Seq: int64(i + 1), Event: e})
+	}
+	return o
+}
+
+func (o *memOutbox) PendingEvents(ctx context.Context, now time.Time, limit int) ([]domain.OutboxEvent, error) {
+	var res []domain.OutboxEvent
+	for _, oe := range o.events {
+		if o.delivered[oe.Seq] || o.dead[oe.Seq] || o.nextAt[oe.Seq].After(now) {
+			continue
+		}
+		if len(res) == limit {
+			break
+		}
+		res = append(res, oe)
+	}
+	return res, nil
+}
+
+func (o *memOutbox) MarkEventDelivered(ctx context.Context, seq int64) error {
+	o.delivered[seq] = true
+	o.attempt(seq)
+	return nil
+}
+
+func (o *memOutbox) RetryEventLater(ctx context.Context, seq int64, reason string, at time.Time, deliveredTo []string) error {
+	o.nextAt[seq] = at
+	for i := range o.events {
+		if o.events[i].Seq == seq {
+			o.events[i].DeliveredTo = deliveredTo
+		}
+	}
+	o.attempt(seq)
+	return nil
+}
+
+func (o *memOutbox) MarkEventDead(ctx context.Context, seq int64, reason string) error {
+	o.dead[seq] = true
+	o.attempt(seq)
+	return nil
+}
+
+func (o *memOutbox) attempt(seq int64) {
+	for i := range o.events {
+		if o.events[i].Seq == seq {
+			o.events[i].Attempts++
+		}
+	}
+}
+
+// flakySink отклоняет первые failures доставок
+type flakySink struct {
+	name     string
+	failures int
+	got      []string
+}
+
+func (s *flakySink) Name() string {
+	return s.name
+}
+
+func (s *flakySink) Deliver(ctx context.Context, e domain.Event) error {
+	s.got = append(s.got, e.ID)
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func newTestRelay(o *memOutbox, sinks ...EventSink) *OutboxRelay {
+	r := NewOutboxRelay(o, &fakeLocker{}, infra.NewStdLogger(), time.Minute, sinks...)
+	// нулевая задержка позволяет повторять событие в следующем же проходе
+	r.BaseBackoff = 0
+	return r
+}
+
+func TestOutboxRelay_DeliversInOrder(t *testing.T) {
+	e1 := domain.NewEvent(domain.EventPRCreated, nil)
+	e2 := domain.NewEvent(domain.EventReviewerAssigned, nil)
+	o := newMemOutbox(e1, e2)
+	sink := &flakySink{}
+	r := newTestRelay(o, sink)
+
+	r.RunOnce(context.Background())
+	r.RunOnce(context.Background())
+
+	if len(sink.got) != 2 || sink.got[0] != e1.ID || sink.got[1] != e2.ID {
+		t.Fatalf("expected events delivered once in order, got %v", sink.got)
+	}
+	if !o.delivered[1] || !o.delivered[2] {
+		t.Fatalf("expected events marked delivered")
+	}
+}
+
+func TestOutboxRelay_RetriesOnlyFailedSinks(t *testing.T) {
+	e := domain.NewEvent(domain.EventPRMerged, nil)
+	o := newMemOutbox(e)
+	ok := &flakySink{name: "ok"}
+	flaky := &flakySink{name: "flaky", failures: 1}
+	r := newTestRelay(o, ok, flaky)
+
+	r.RunOnce(context.Background())
+	if o.delivered[1] || o.events[0].Attempts != 1 {
+		t.Fatalf("expected event to stay pending after failure")
+	}
+	r.RunOnce(context.Background())
+
+	if !o.delivered[1] {
+		t.Fatalf("expected event delivered on retry")
+	}
+	if len(ok.got) != 1 || len(flaky.got) != 2 {
+		t.Fatalf("expected retry only to the failed sink, got ok=%v flaky=%v", ok.got, flaky.got)
+	}
+}
+
+// countingNotifier считает уведомления, как если бы каждое было сообщением в чат
+type countingNotifier struct {
+	posts int
+}
+
+func (n *countingNotifier) ReviewersAssigned(ctx context.Context, prID string, reviewerIDs []string) {
+	n.posts++
+}
+
+func (n *countingNotifier) ReviewerReassigned(ctx context.Context, r domain.Replacement) {
+	n.posts++
+}
+
+func (n *countingNotifier) PRMerged(ctx context.Context, pr domain.PullRequest) {
+	n.posts++
+}
+
+func TestOutboxRelay_NoDuplicateChatPostsOnRetry(t *testing.T) {
+	o := newMemOutbox(domain.NewReviewerAssignedEvent("pr-1", "u2"))
+	chat := &countingNotifier{}
+	webhooks := &flakySink{name: "webhook", failures: 3}
+	r := newTestRelay(o, usecase.NewNotifierSink(chat), webhooks)
+
+	for i := 0; i < 4; i++ {
+		r.RunOnce(context.Background())
+	}
+
+	if !o.delivered[1] || len(webhooks.got) != 4 {
+		t.Fatalf("expected event delivered after 4 webhook attempts, got %d, delivered=%v", len(webhooks.got), o.delivered[1])
+	}
+	if chat.posts != 1 {
+		t.Fatalf("expected one chat post despite retries, got %d", chat.posts)
+	}
+}
+
+func TestOutboxRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	o := newMemOutbox(domain.NewEvent(domain.EventPRCreated, nil))
+	sink := &flakySink{failures: 10}
+	r := newTestRelay(o, sink)
+	r.MaxAttempts = 3
+
+	for i := 0; i < 5; i++ {
+		r.RunOnce(context.Background())
+	}
+
+	if len(sink.got) != 3 || !o.dead[1] {
+		t.Fatalf("expected 3 attempts and dead event, got %d attempts, dead=%v", len(sink.got), o.dead[1])
+	}
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	r := NewOutboxRelay(newMemOutbox(), &fakeLocker{}, infra.NewStdLogger(), time.Minute)
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{30, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := r.backoff(tt.attempt); got != tt.want {
+			t.Fatalf("backoff(%d): expected %s, got %s", tt.attempt, tt.want, got)
+		}
+	}
+}
+
+func TestOutboxRelay_SkipsWhenLockHeld(t *testing.T) {
+	o := newMemOutbox(domain.NewEvent(domain.EventPRCreated, nil))
+	sink := &flakySink{}
+	r := NewOutboxRelay(o, &fakeLocker{heldElsewhere: true}, infra.NewStdLogger(), time.Minute, sink)
+
+	r.RunOnce(context.Background())
+
+	if len(sink.got) != 0 {
+		t.Fatalf("expected pass to be skipped, got %v", sink.got)
+	}
+}
@@ -0,0 +1,2 @@
+-- стратегия выбора ревьюверов; NULL = стратегия по умолчанию из конфигурации
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS reviewer_strategy TEXT NULL;
@@ -0,0 +1,7 @@
+-- число ревьюверов на PR для команды
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS min_reviewers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS max_reviewers INTEGER NOT NULL DEFAULT 2;
+-- повторный запуск не должен падать на существующем ограничении
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_reviewer_limits_check;
+ALTER TABLE teams ADD CONSTRAINT teams_reviewer_limits_check
+  CHECK (min_reviewers >= 0 AND max_reviewers >= 1 AND min_reviewers <= max_reviewers);
@@ -0,0 +1,4 @@
+-- лимит одновременных ревью; 0 = без ограничения.
+-- is_active больше не используется как признак занятости ревьювера
+ALTER TABLE users ADD COLUMN IF NOT EXISTS max_concurrent_reviews INTEGER NOT NULL DEFAULT 0
+  CHECK (max_concurrent_reviews >= 0);
@@ -0,0 +1,8 @@
+-- резервные команды, из которых назначаются ревьюверы, если в команде не хватает кандидатов
+CREATE TABLE IF NOT EXISTS team_fallbacks (
+  team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  fallback_team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  PRIMARY KEY (team_id, fallback_team_id),
+  CHECK (team_id <> fallback_team_id)
+);
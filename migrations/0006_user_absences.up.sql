@@ -0,0 +1,10 @@
+-- календарь отсутствий: в период [starts_at, ends_at) пользователь не назначается ревьювером
+CREATE TABLE IF NOT EXISTS user_absences (
+  id SERIAL PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS idx_user_absences_user_period ON user_absences (user_id, starts_at, ends_at);
@@ -0,0 +1,2 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS tags;
+ALTER TABLE users DROP COLUMN IF EXISTS tags;
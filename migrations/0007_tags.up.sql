@@ -0,0 +1,3 @@
+-- теги экспертизы пользователей и затрагиваемых областей PR (backend, db, frontend ...)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- содержимое CODEOWNERS команды; по нему назначаются владельцы изменённых файлов
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS codeowners TEXT NULL;
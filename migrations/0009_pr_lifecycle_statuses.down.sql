@@ -0,0 +1,5 @@
+-- откат: черновики, закрытые и переоткрытые PR становятся OPEN - закрытый PR не был
+-- слит, поэтому MERGED для него неверен
+UPDATE pull_requests SET status_id = (SELECT id FROM pr_statuses WHERE name='OPEN')
+WHERE status_id IN (SELECT id FROM pr_statuses WHERE name IN ('DRAFT', 'CLOSED', 'REOPENED'));
+DELETE FROM pr_statuses WHERE name IN ('DRAFT', 'CLOSED', 'REOPENED');
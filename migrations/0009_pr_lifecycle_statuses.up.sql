@@ -0,0 +1,4 @@
+-- DRAFT - черновик, CLOSED - отклонён без merge, REOPENED - открыт повторно после закрытия
+INSERT INTO pr_statuses (name) VALUES ('DRAFT') ON CONFLICT DO NOTHING;
+INSERT INTO pr_statuses (name) VALUES ('CLOSED') ON CONFLICT DO NOTHING;
+INSERT INTO pr_statuses (name) VALUES ('REOPENED') ON CONFLICT DO NOTHING;
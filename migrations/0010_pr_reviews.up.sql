@@ -0,0 +1,10 @@
+-- решения ревьюверов; актуальным считается последнее решение ревьювера по PR
+CREATE TABLE IF NOT EXISTS pr_reviews (
+  id SERIAL PRIMARY KEY,
+  pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+  reviewer_id TEXT NOT NULL REFERENCES users(id),
+  decision TEXT NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED', 'COMMENTED')),
+  comment TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_pr_reviews_pr_reviewer ON pr_reviews (pr_id, reviewer_id, created_at DESC);
@@ -0,0 +1,4 @@
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS merge_forced_by;
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_required_approvals_check;
+ALTER TABLE teams DROP COLUMN IF EXISTS allow_changes_requested;
+ALTER TABLE teams DROP COLUMN IF EXISTS required_approvals;
@@ -0,0 +1,7 @@
+-- политика merge команды и отметка о merge в обход неё
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS allow_changes_requested BOOLEAN NOT NULL DEFAULT FALSE;
+-- повторный запуск не должен падать на существующем ограничении
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_required_approvals_check;
+ALTER TABLE teams ADD CONSTRAINT teams_required_approvals_check CHECK (required_approvals >= 0);
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS merge_forced_by TEXT;
@@ -0,0 +1,3 @@
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_review_sla_check;
+ALTER TABLE teams DROP COLUMN IF EXISTS review_sla_hours;
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS assigned_at;
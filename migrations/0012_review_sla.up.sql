@@ -0,0 +1,11 @@
+-- время назначения ревьювера; для существующих назначений берётся время создания PR
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMPTZ;
+UPDATE pr_reviewers rv SET assigned_at = pr.created_at FROM pull_requests pr WHERE pr.id = rv.pr_id AND rv.assigned_at IS NULL;
+ALTER TABLE pr_reviewers ALTER COLUMN assigned_at SET DEFAULT now();
+ALTER TABLE pr_reviewers ALTER COLUMN assigned_at SET NOT NULL;
+
+-- SLA ревью команды в часах, 0 - без SLA
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS review_sla_hours INTEGER NOT NULL DEFAULT 0;
+-- повторный запуск не должен падать на существующем ограничении
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_review_sla_check;
+ALTER TABLE teams ADD CONSTRAINT teams_review_sla_check CHECK (review_sla_hours >= 0);
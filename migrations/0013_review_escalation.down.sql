@@ -0,0 +1,4 @@
+ALTER TABLE pr_reviewers DROP COLUMN IF EXISTS escalated_at;
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_escalation_check;
+ALTER TABLE teams DROP COLUMN IF EXISTS escalation_action;
+ALTER TABLE teams DROP COLUMN IF EXISTS escalation_hours;
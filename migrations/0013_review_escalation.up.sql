@@ -0,0 +1,10 @@
+-- эскалация зависших ревью: через сколько часов без решения и что делать
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS escalation_hours INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS escalation_action TEXT NOT NULL DEFAULT 'reassign';
+-- повторный запуск не должен падать на существующем ограничении
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_escalation_check;
+ALTER TABLE teams ADD CONSTRAINT teams_escalation_check
+  CHECK (escalation_hours >= 0 AND escalation_action IN ('reassign', 'add_reviewer'));
+
+-- когда к зависшему ревью добавили ещё одного ревьювера; такое ревью повторно не эскалируется
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
@@ -0,0 +1,17 @@
+-- события, записанные в одной транзакции с изменениями PR; их доставляет relay
+CREATE TABLE IF NOT EXISTS outbox (
+  id BIGSERIAL PRIMARY KEY,
+  event_id TEXT NOT NULL UNIQUE,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error TEXT,
+  delivered_at TIMESTAMPTZ,
+  -- доставка прекращена после исчерпания попыток
+  dead_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (next_attempt_at, id)
+  WHERE delivered_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,7 @@
+-- соответствие логинов во внешних системах (GitHub и др.) пользователям сервиса
+CREATE TABLE IF NOT EXISTS external_accounts (
+  provider TEXT NOT NULL,
+  login TEXT NOT NULL,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY (provider, login)
+);
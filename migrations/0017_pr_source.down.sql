@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_external_accounts_user;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS source;
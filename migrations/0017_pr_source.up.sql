@@ -0,0 +1,4 @@
+-- внешняя система, из которой пришёл PR; пустая строка - PR создан через API сервиса
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_external_accounts_user ON external_accounts (provider, user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS chat_handle;
+ALTER TABLE teams DROP COLUMN IF EXISTS chat_channel;
+ALTER TABLE teams DROP COLUMN IF EXISTS chat_webhook_url;
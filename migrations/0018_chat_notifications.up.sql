@@ -0,0 +1,6 @@
+-- уведомления в чат: incoming webhook команды (Slack/Mattermost) и канал, если его нужно переопределить
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS chat_webhook_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS chat_channel TEXT NOT NULL DEFAULT '';
+
+-- как упомянуть пользователя в чате, например "@alice" или "<@U024BE7LH>"
+ALTER TABLE users ADD COLUMN IF NOT EXISTS chat_handle TEXT NOT NULL DEFAULT '';
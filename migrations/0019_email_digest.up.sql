@@ -0,0 +1,5 @@
+-- адрес для писем и отказ от ежедневного дайджеста ожидающих ревью
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+-- день последнего отправленного дайджеста: повторные проходы и другие реплики его не дублируют
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_sent_on DATE;
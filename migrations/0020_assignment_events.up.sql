@@ -0,0 +1,26 @@
+-- журнал изменений состава ревьюверов PR; пишется в одной транзакции с изменением.
+-- Внешних ключей нет: журнал должен пережить удаление PR и пользователей
+CREATE TABLE IF NOT EXISTS assignment_events (
+  id BIGSERIAL PRIMARY KEY,
+  pr_id TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  old_reviewer_id TEXT,
+  new_reviewer_id TEXT,
+  reason TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CHECK (old_reviewer_id IS NOT NULL OR new_reviewer_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_events_pr ON assignment_events (pr_id, id);
+
+-- журнал только дополняется
+CREATE OR REPLACE FUNCTION assignment_events_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'assignment_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS assignment_events_append_only ON assignment_events;
+CREATE TRIGGER assignment_events_append_only
+  BEFORE UPDATE OR DELETE ON assignment_events
+  FOR EACH ROW EXECUTE FUNCTION assignment_events_append_only();
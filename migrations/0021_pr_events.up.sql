@@ -0,0 +1,96 @@
+-- поток событий PR - источник истины о PR и его ревьюверах;
+-- pull_requests и pr_reviewers - проекции потока
+CREATE TABLE IF NOT EXISTS pr_events (
+  pr_id TEXT NOT NULL,
+  version INT NOT NULL,
+  type TEXT NOT NULL,
+  data JSONB NOT NULL,
+  occurred_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (pr_id, version)
+);
+
+-- поток только дополняется
+CREATE OR REPLACE FUNCTION pr_events_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'pr_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS pr_events_append_only ON pr_events;
+CREATE TRIGGER pr_events_append_only
+  BEFORE UPDATE OR DELETE ON pr_events
+  FOR EACH ROW EXECUTE FUNCTION pr_events_append_only();
+
+-- потоки существующих PR. Назначения и замены ревьюверов берутся из журнала назначений
+-- (0020) с их временем; ревьюверы, назначенные до появления журнала, - из pr_reviewers.
+-- Время прежних смен статуса не сохранялось: merge датируется merged_at, а CLOSED и
+-- REOPENED - созданием PR. Поэтому history_from в created - момент миграции: раньше
+-- него состояние PR по потоку не восстанавливается
+WITH logged AS (
+  SELECT ae.id, ae.pr_id, ae.old_reviewer_id, ae.new_reviewer_id, ae.created_at
+  FROM assignment_events ae
+  JOIN pull_requests pr ON pr.id = ae.pr_id
+  WHERE ae.new_reviewer_id IS NOT NULL
+),
+-- первое упоминание ревьювера в журнале; если он в нём сразу заменён, то назначен до журнала
+first_seen AS (
+  SELECT DISTINCT ON (l.pr_id, r.reviewer_id) l.pr_id, r.reviewer_id, r.replaced
+  FROM logged l
+  CROSS JOIN LATERAL (VALUES (l.old_reviewer_id, TRUE), (l.new_reviewer_id, FALSE)) AS r(reviewer_id, replaced)
+  WHERE r.reviewer_id IS NOT NULL
+  ORDER BY l.pr_id, r.reviewer_id, l.id
+)
+INSERT INTO pr_events (pr_id, version, type, data, occurred_at)
+SELECT pr_id,
+       ROW_NUMBER() OVER (PARTITION BY pr_id ORDER BY ord = 0 DESC, occurred_at, ord, seq, reviewer_id),
+       type, data, occurred_at
+FROM (
+  SELECT pr.id AS pr_id, 0 AS ord, 0::bigint AS seq, '' AS reviewer_id, 'created' AS type,
+         jsonb_strip_nulls(jsonb_build_object(
+           'title', pr.title,
+           'author_id', pr.author_id,
+           'tags', CASE WHEN cardinality(pr.tags) > 0 THEN to_jsonb(pr.tags) END,
+           'source', NULLIF(pr.source, ''),
+           'status', CASE WHEN st.name = 'DRAFT' THEN 'DRAFT' ELSE 'OPEN' END,
+           'history_from', now()
+         )) AS data,
+         pr.created_at AS occurred_at
+  FROM pull_requests pr
+  JOIN pr_statuses st ON st.id = pr.status_id
+  UNION ALL
+  -- назначены до журнала и до сих пор ревьюверы
+  SELECT rv.pr_id, 1, 0, rv.reviewer_id, 'reviewer_assigned',
+         jsonb_build_object('reviewer_id', rv.reviewer_id), rv.assigned_at
+  FROM pr_reviewers rv
+  WHERE NOT EXISTS (SELECT 1 FROM first_seen f WHERE f.pr_id = rv.pr_id AND f.reviewer_id = rv.reviewer_id)
+  UNION ALL
+  -- назначены до журнала и уже заменены; время назначения неизвестно
+  SELECT f.pr_id, 1, 0, f.reviewer_id, 'reviewer_assigned',
+         jsonb_build_object('reviewer_id', f.reviewer_id), pr.created_at
+  FROM first_seen f
+  JOIN pull_requests pr ON pr.id = f.pr_id
+  WHERE f.replaced
+  UNION ALL
+  SELECT l.pr_id, 2, l.id, l.new_reviewer_id,
+         CASE WHEN l.old_reviewer_id IS NULL THEN 'reviewer_assigned' ELSE 'reviewer_replaced' END,
+         jsonb_strip_nulls(jsonb_build_object('reviewer_id', l.new_reviewer_id, 'old_reviewer_id', l.old_reviewer_id)),
+         l.created_at
+  FROM logged l
+  UNION ALL
+  SELECT rv.pr_id, 3, 0, rv.reviewer_id, 'reviewer_escalated',
+         jsonb_build_object('reviewer_id', rv.reviewer_id), rv.escalated_at
+  FROM pr_reviewers rv
+  WHERE rv.escalated_at IS NOT NULL
+  UNION ALL
+  SELECT pr.id, 4, 0, '', 'merged',
+         jsonb_strip_nulls(jsonb_build_object('forced_by', pr.merge_forced_by)), COALESCE(pr.merged_at, pr.created_at)
+  FROM pull_requests pr
+  JOIN pr_statuses st ON st.id = pr.status_id
+  WHERE st.name = 'MERGED'
+  UNION ALL
+  SELECT pr.id, 4, 0, '', 'status_changed', jsonb_build_object('status', st.name), pr.created_at
+  FROM pull_requests pr
+  JOIN pr_statuses st ON st.id = pr.status_id
+  WHERE st.name IN ('CLOSED', 'REOPENED')
+) e
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN IF EXISTS digest_failed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS digest_failures;
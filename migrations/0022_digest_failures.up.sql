@@ -0,0 +1,4 @@
+-- неудачные попытки отправить дайджест: следующая попытка откладывается, а после
+-- нескольких неудач за день дайджест за этот день пропускается
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_failed_at TIMESTAMPTZ;
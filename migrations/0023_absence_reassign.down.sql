@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_absences_pending_reassign;
+ALTER TABLE user_absences DROP COLUMN IF EXISTS reassigned_at;
+ALTER TABLE user_absences DROP COLUMN IF EXISTS reassign_open_reviews;
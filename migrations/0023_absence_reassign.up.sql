@@ -0,0 +1,7 @@
+-- переназначение открытых ревью при начале отсутствия: флаг ставится при создании
+-- отсутствия, а reassigned_at - когда переназначение выполнено
+ALTER TABLE user_absences ADD COLUMN IF NOT EXISTS reassign_open_reviews BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_absences ADD COLUMN IF NOT EXISTS reassigned_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_user_absences_pending_reassign
+  ON user_absences (starts_at) WHERE reassign_open_reviews AND reassigned_at IS NULL;
@@ -0,0 +1,2 @@
+-- получатели outbox, уже принявшие событие: при повторе событие отправляется только остальным
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS delivered_to TEXT[] NOT NULL DEFAULT '{}';